@@ -6,6 +6,7 @@ import (
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -60,3 +61,7 @@ func TestDefragmentation(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(defragmentedMemory, fragmentedMemory))
 	assert.True(t, reflect.DeepEqual(defragmentedPointers, fragmentedPointers))
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
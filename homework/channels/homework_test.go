@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -56,3 +57,7 @@ func TestWorkerPool(t *testing.T) {
 
 	assert.Equal(t, int32(6), counter.Load())
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
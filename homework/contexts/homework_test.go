@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 type Group struct {
@@ -72,3 +73,7 @@ func TestErrGroupWithError(t *testing.T) {
 	assert.Equal(t, int32(0), counter.Load())
 	assert.Error(t, err)
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
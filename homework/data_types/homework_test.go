@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -47,3 +48,7 @@ func TestСonversion(t *testing.T) {
 		})
 	}
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -62,3 +63,7 @@ func TestMultiError(t *testing.T) {
 	expectedMessage := "2 errors occurred:\n\t* error 1\n\t* error 2\n"
 	assert.EqualError(t, err, expectedMessage)
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 func Map(data []int, action func(int) int) []int {
@@ -173,3 +174,7 @@ func TestReduce(t *testing.T) {
 		})
 	}
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
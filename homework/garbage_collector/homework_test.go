@@ -7,6 +7,7 @@ import (
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -87,3 +88,7 @@ func TestTrace(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(expectedPointers, pointers))
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
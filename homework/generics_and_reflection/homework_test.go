@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -107,3 +108,7 @@ func TestSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
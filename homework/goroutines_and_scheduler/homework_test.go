@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 type Task struct {
@@ -61,3 +62,7 @@ func TestTrace(t *testing.T) {
 	task = scheduler.GetTask()
 	assert.Equal(t, task3, task)
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -71,3 +72,7 @@ func TestDIContainer(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, paymentService)
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
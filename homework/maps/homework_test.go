@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -203,3 +204,7 @@ func TestOrderedMap(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(expectedKeys, keys))
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 // go test -v homework_test.go
@@ -120,3 +121,7 @@ func TestCircularQueue(t *testing.T) {
 	assert.True(t, queue.Empty())
 	assert.False(t, queue.Full())
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -6,6 +6,7 @@ import (
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 type COWBuffer struct {
@@ -102,3 +103,7 @@ func TestCOWBuffer(t *testing.T) {
 
 	copy2.Close()
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -6,6 +6,7 @@ import (
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 type Option func(*GamePerson)
@@ -278,3 +279,7 @@ func TestGamePerson(t *testing.T) {
 	assert.False(t, person.HasGun())
 	assert.Equal(t, personType, person.Type())
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang_course/lessons/goroutines_and_scheduler/leakcheck"
 )
 
 type RWMutex struct {
@@ -121,3 +122,7 @@ func TestRWMutexWithWriterPriority(t *testing.T) {
 	assert.True(t, mutualExlusionWithWriter.Load())
 	assert.Equal(t, int32(1), readersCount.Load())
 }
+
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
@@ -0,0 +1,190 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrClosed       = errors.New("broker is closed")
+	ErrInvalidTopic = errors.New("invalid topic")
+	ErrSlowConsumer = errors.New("slow consumer disconnected")
+)
+
+type Message[T any] struct {
+	Topic    string
+	Payload  T
+	Sequence uint64
+}
+
+type Option func(*options)
+
+type options struct {
+	replay int
+}
+
+// WithReplay keeps the last messages, new subscribers
+// receive the matching ones before live messages
+func WithReplay(count int) Option {
+	return func(o *options) {
+		o.replay = count
+	}
+}
+
+// Broker delivers messages to subscribers of matching topics,
+// every subscriber has its own buffer so a slow one doesn't
+// delay others unless it uses the Block policy
+type Broker[T any] struct {
+	mutex       sync.RWMutex
+	subscribers map[uint64]*Subscription[T]
+	history     []Message[T]
+	replay      int
+	sequence    uint64
+	nextID      uint64
+	closed      bool
+}
+
+func New[T any](opts ...Option) *Broker[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Broker[T]{
+		subscribers: make(map[uint64]*Subscription[T]),
+		replay:      max(o.replay, 0),
+	}
+}
+
+// Subscribe registers a subscriber for topics matching the pattern
+func (b *Broker[T]) Subscribe(pattern string, opts ...SubscribeOption) (*Subscription[T], error) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	o := subscribeOptions{buffer: defaultBuffer, policy: Drop}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	var replayed []Message[T]
+	for _, message := range b.history {
+		if match(segments, message.Topic) {
+			replayed = append(replayed, message)
+		}
+	}
+
+	// only the newest messages fit into the buffer
+	buffer := max(o.buffer, 0)
+	if len(replayed) > buffer {
+		replayed = replayed[len(replayed)-buffer:]
+	}
+
+	b.nextID++
+	subscription := &Subscription[T]{
+		id:      b.nextID,
+		pattern: pattern,
+		broker:  b,
+		match:   segments,
+		policy:  o.policy,
+		channel: make(chan Message[T], buffer),
+		done:    make(chan struct{}),
+		turn:    make(chan struct{}),
+	}
+	close(subscription.turn)
+
+	// nobody else sends into the new channel yet
+	for _, message := range replayed {
+		subscription.channel <- message
+	}
+
+	b.subscribers[subscription.id] = subscription
+	return subscription, nil
+}
+
+// Publish delivers the message to all matching subscribers, the context
+// limits waiting for subscribers with the Block policy
+func (b *Broker[T]) Publish(ctx context.Context, topic string, payload T) error {
+	if err := validateTopic(topic); err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return ErrClosed
+	}
+
+	b.sequence++
+	message := Message[T]{Topic: topic, Payload: payload, Sequence: b.sequence}
+	if b.replay > 0 {
+		if len(b.history) == b.replay {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, message)
+	}
+
+	// turns are taken in the order of sequence numbers
+	var deliveries []delivery[T]
+	for _, subscription := range b.subscribers {
+		if match(subscription.match, topic) {
+			next := make(chan struct{})
+			deliveries = append(deliveries, delivery[T]{subscription: subscription, turn: subscription.turn, next: next})
+			subscription.turn = next
+		}
+	}
+	b.mutex.Unlock()
+
+	// delivery goes without the broker lock, so blocked
+	// subscribers don't prevent others from unsubscribing
+	var errs []error
+	for _, d := range deliveries {
+		if err := d.subscription.deliver(ctx, message, d.turn, d.next); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Broker[T]) remove(id uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.subscribers, id)
+}
+
+// Close closes channels of all subscribers
+func (b *Broker[T]) Close() {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return
+	}
+
+	b.closed = true
+	subscribers := b.subscribers
+	b.subscribers = nil
+	b.history = nil
+	b.mutex.Unlock()
+
+	for _, subscription := range subscribers {
+		subscription.shutdown(ErrClosed)
+	}
+}
+
+func (b *Broker[T]) Subscribers() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.subscribers)
+}
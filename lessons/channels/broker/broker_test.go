@@ -0,0 +1,305 @@
+package broker
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func receive[T any](t *testing.T, subscription *Subscription[T], count int) []Message[T] {
+	t.Helper()
+
+	var messages []Message[T]
+	for i := 0; i < count; i++ {
+		select {
+		case message := <-subscription.C():
+			messages = append(messages, message)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d messages", i, count)
+		}
+	}
+
+	return messages
+}
+
+func payloads[T any](messages []Message[T]) []T {
+	result := make([]T, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, message.Payload)
+	}
+	return result
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		topic   string
+		matched bool
+	}{
+		"exact":                  {pattern: "orders.created", topic: "orders.created", matched: true},
+		"different":              {pattern: "orders.created", topic: "orders.deleted", matched: false},
+		"single wildcard":        {pattern: "orders.*", topic: "orders.created", matched: true},
+		"single wildcard depth":  {pattern: "orders.*", topic: "orders.created.eu", matched: false},
+		"middle wildcard":        {pattern: "*.created", topic: "users.created", matched: true},
+		"tail wildcard":          {pattern: "orders.>", topic: "orders.created.eu", matched: true},
+		"tail needs one segment": {pattern: "orders.>", topic: "orders", matched: false},
+		"everything":             {pattern: ">", topic: "a.b.c", matched: true},
+		"shorter topic":          {pattern: "orders.created", topic: "orders", matched: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			segments, err := parsePattern(test.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, test.matched, match(segments, test.topic))
+		})
+	}
+}
+
+func TestInvalidTopics(t *testing.T) {
+	broker := New[int]()
+	_, err := broker.Subscribe("orders.>.eu")
+	assert.ErrorIs(t, err, ErrInvalidTopic)
+	_, err = broker.Subscribe("orders..created")
+	assert.ErrorIs(t, err, ErrInvalidTopic)
+	assert.ErrorIs(t, broker.Publish(context.Background(), "orders.*", 1), ErrInvalidTopic)
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	broker := New[string]()
+	orders, err := broker.Subscribe("orders.*")
+	require.NoError(t, err)
+	all, err := broker.Subscribe(">")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, broker.Publish(ctx, "orders.created", "first"))
+	require.NoError(t, broker.Publish(ctx, "users.created", "second"))
+	require.NoError(t, broker.Publish(ctx, "orders.paid", "third"))
+
+	assert.Equal(t, []string{"first", "third"}, payloads(receive(t, orders, 2)))
+	messages := receive(t, all, 3)
+	assert.Equal(t, []string{"first", "second", "third"}, payloads(messages))
+	assert.Equal(t, "users.created", messages[1].Topic)
+	assert.Equal(t, uint64(2), messages[1].Sequence)
+}
+
+func TestReplay(t *testing.T) {
+	broker := New[int](WithReplay(3))
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		topic := "even"
+		if i%2 != 0 {
+			topic = "odd"
+		}
+		require.NoError(t, broker.Publish(ctx, topic, i))
+	}
+
+	// history has 3, 4 and 5
+	odd, err := broker.Subscribe("odd")
+	require.NoError(t, err)
+	all, err := broker.Subscribe(">", WithBuffer(2))
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Publish(ctx, "odd", 7))
+	assert.Equal(t, []int{3, 5, 7}, payloads(receive(t, odd, 3)))
+
+	// replay is limited by the buffer, so the live message is dropped
+	assert.Equal(t, []int{4, 5}, payloads(receive(t, all, 2)))
+	assert.Equal(t, uint64(1), all.Dropped())
+}
+
+func TestDropPolicy(t *testing.T) {
+	broker := New[int]()
+	subscription, err := broker.Subscribe("events", WithBuffer(2))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, broker.Publish(context.Background(), "events", i))
+	}
+
+	assert.Equal(t, []int{0, 1}, payloads(receive(t, subscription, 2)))
+	assert.Equal(t, uint64(3), subscription.Dropped())
+}
+
+func TestBlockPolicy(t *testing.T) {
+	broker := New[int]()
+	subscription, err := broker.Subscribe("events", WithBuffer(1), WithPolicy(Block))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, broker.Publish(ctx, "events", 1))
+
+	published := make(chan error)
+	go func() {
+		published <- broker.Publish(ctx, "events", 2)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("publisher must wait for the subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, []int{1, 2}, payloads(receive(t, subscription, 2)))
+	require.NoError(t, <-published)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.NoError(t, broker.Publish(timeout, "events", 3))
+	assert.ErrorIs(t, broker.Publish(timeout, "events", 4), context.DeadlineExceeded)
+}
+
+func TestFailedDeliveryDoesNotStopOthers(t *testing.T) {
+	broker := New[int]()
+	var blocked []*Subscription[int]
+	for i := 0; i < 3; i++ {
+		subscription, err := broker.Subscribe("events", WithBuffer(0), WithPolicy(Block))
+		require.NoError(t, err)
+		blocked = append(blocked, subscription)
+	}
+
+	var fast []*Subscription[int]
+	for i := 0; i < 5; i++ {
+		subscription, err := broker.Subscribe("events", WithBuffer(10))
+		require.NoError(t, err)
+		fast = append(fast, subscription)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, broker.Publish(ctx, "events", i), context.Canceled)
+	}
+
+	for _, subscription := range fast {
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, payloads(receive(t, subscription, 10)))
+		assert.Zero(t, subscription.Dropped())
+	}
+
+	for _, subscription := range blocked {
+		subscription.Unsubscribe()
+	}
+}
+
+func TestConcurrentPublishersKeepOrder(t *testing.T) {
+	const publishers, messages = 8, 200
+
+	broker := New[int]()
+	subscription, err := broker.Subscribe("events", WithBuffer(4), WithPolicy(Block))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				assert.NoError(t, broker.Publish(context.Background(), "events", j))
+			}
+		}()
+	}
+
+	var last uint64
+	for _, message := range receive(t, subscription, publishers*messages) {
+		require.Greater(t, message.Sequence, last)
+		last = message.Sequence
+	}
+
+	wg.Wait()
+}
+
+func TestUnsubscribeUnblocksPublisher(t *testing.T) {
+	broker := New[int]()
+	subscription, err := broker.Subscribe("events", WithBuffer(0), WithPolicy(Block))
+	require.NoError(t, err)
+
+	published := make(chan error)
+	go func() {
+		published <- broker.Publish(context.Background(), "events", 1)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	subscription.Unsubscribe()
+	subscription.Unsubscribe()
+
+	require.NoError(t, <-published)
+	_, ok := <-subscription.C()
+	assert.False(t, ok)
+	assert.NoError(t, subscription.Err())
+	assert.Zero(t, broker.Subscribers())
+}
+
+func TestDisconnectPolicy(t *testing.T) {
+	broker := New[int]()
+	slow, err := broker.Subscribe("events", WithBuffer(1), WithPolicy(Disconnect))
+	require.NoError(t, err)
+	fast, err := broker.Subscribe("events", WithBuffer(10))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, broker.Publish(context.Background(), "events", i))
+	}
+
+	assert.Equal(t, []int{0}, payloads(receive(t, slow, 1)))
+	_, ok := <-slow.C()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+	assert.Equal(t, []int{0, 1, 2}, payloads(receive(t, fast, 3)))
+	assert.Equal(t, 1, broker.Subscribers())
+}
+
+func TestClose(t *testing.T) {
+	broker := New[int]()
+	subscription, err := broker.Subscribe("events")
+	require.NoError(t, err)
+
+	broker.Close()
+	broker.Close()
+
+	_, ok := <-subscription.C()
+	assert.False(t, ok)
+	assert.ErrorIs(t, subscription.Err(), ErrClosed)
+	assert.ErrorIs(t, broker.Publish(context.Background(), "events", 1), ErrClosed)
+	_, err = broker.Subscribe("events")
+	assert.ErrorIs(t, err, ErrClosed)
+	subscription.Unsubscribe()
+}
+
+func TestNoLeaks(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	broker := New[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		subscription, err := broker.Subscribe("events.>", WithPolicy(Block))
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range subscription.C() {
+			}
+		}()
+
+		if i%2 == 0 {
+			defer subscription.Unsubscribe()
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, broker.Publish(context.Background(), "events.tick", i))
+	}
+
+	broker.Close()
+	wg.Wait()
+
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
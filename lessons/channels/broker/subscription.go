@@ -0,0 +1,162 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBuffer = 16
+
+// Policy defines what happens when the buffer of a subscriber is full
+type Policy int
+
+const (
+	Drop       Policy = iota // the message is skipped for this subscriber
+	Block                    // the publisher waits for free space
+	Disconnect               // the subscriber is unsubscribed with ErrSlowConsumer
+)
+
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer int
+	policy Policy
+}
+
+func WithBuffer(buffer int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.buffer = buffer
+	}
+}
+
+func WithPolicy(policy Policy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+	}
+}
+
+type Subscription[T any] struct {
+	id      uint64
+	pattern string
+	broker  *Broker[T]
+	match   []string
+	policy  Policy
+
+	channel  chan Message[T]
+	done     chan struct{} // unblocks publishers waiting for this subscriber
+	doneOnce sync.Once
+
+	mutex   sync.Mutex // serializes sending and closing of the channel
+	closed  bool
+	err     error
+	dropped atomic.Uint64
+
+	turn chan struct{} // closed when the last delivery is over, guarded by the broker
+}
+
+type delivery[T any] struct {
+	subscription *Subscription[T]
+	turn         chan struct{}
+	next         chan struct{}
+}
+
+// C returns the channel of messages, it is closed after unsubscription
+func (s *Subscription[T]) C() <-chan Message[T] {
+	return s.channel
+}
+
+func (s *Subscription[T]) Pattern() string {
+	return s.pattern
+}
+
+// Dropped returns the number of messages skipped with the Drop policy
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Err explains why the channel was closed
+func (s *Subscription[T]) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.err
+}
+
+// Unsubscribe stops delivery and closes the channel, it is safe to call many times
+func (s *Subscription[T]) Unsubscribe() {
+	s.broker.remove(s.id)
+	s.shutdown(nil)
+}
+
+func (s *Subscription[T]) shutdown(err error) {
+	s.doneOnce.Do(func() { close(s.done) })
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closeLocked(err)
+}
+
+func (s *Subscription[T]) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.err = err
+	close(s.channel)
+}
+
+// deliver waits for the turn, so concurrent publishers
+// can't reorder messages, and closes next when it is done
+func (s *Subscription[T]) deliver(ctx context.Context, message Message[T], turn, next chan struct{}) error {
+	select {
+	case <-turn:
+	default:
+		select {
+		case <-turn:
+		case <-ctx.Done():
+			// the turn is passed once the previous delivery is over
+			go func() {
+				<-turn
+				close(next)
+			}()
+			return ctx.Err()
+		}
+	}
+	defer close(next)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.channel <- message:
+		case <-s.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case Disconnect:
+		select {
+		case s.channel <- message:
+		default:
+			s.doneOnce.Do(func() { close(s.done) })
+			s.closeLocked(ErrSlowConsumer)
+			s.broker.remove(s.id)
+		}
+	default:
+		select {
+		case s.channel <- message:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+
+	return nil
+}
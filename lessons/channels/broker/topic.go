@@ -0,0 +1,50 @@
+package broker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// topics are dot-separated like "orders.created", in patterns
+// "*" matches one segment and ">" matches one or more trailing segments
+const (
+	separator      = "."
+	singleWildcard = "*"
+	tailWildcard   = ">"
+)
+
+func validateTopic(topic string) error {
+	for _, segment := range strings.Split(topic, separator) {
+		if segment == "" || segment == singleWildcard || segment == tailWildcard {
+			return fmt.Errorf("%w: %q", ErrInvalidTopic, topic)
+		}
+	}
+
+	return nil
+}
+
+func parsePattern(pattern string) ([]string, error) {
+	segments := strings.Split(pattern, separator)
+	for i, segment := range segments {
+		if segment == "" || (segment == tailWildcard && i != len(segments)-1) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTopic, pattern)
+		}
+	}
+
+	return segments, nil
+}
+
+func match(pattern []string, topic string) bool {
+	segments := strings.Split(topic, separator)
+	for i, part := range pattern {
+		if part == tailWildcard {
+			return len(segments) > i
+		}
+
+		if i >= len(segments) || (part != singleWildcard && part != segments[i]) {
+			return false
+		}
+	}
+
+	return len(segments) == len(pattern)
+}
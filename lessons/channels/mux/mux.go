@@ -0,0 +1,324 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+)
+
+var ErrClosed = errors.New("mux is closed")
+
+// Mode defines which input is read when several inputs are ready,
+// plain select chooses randomly
+type Mode int
+
+const (
+	StrictPriority Mode = iota // inputs with a higher priority are always read first
+	WeightedFair               // ready inputs get shares proportional to weights
+)
+
+type ID uint64
+
+type Message[T any] struct {
+	Input ID
+	Value T
+}
+
+type Option func(*options)
+
+type options struct {
+	mode   Mode
+	buffer int
+}
+
+func WithMode(mode Mode) Option {
+	return func(o *options) {
+		o.mode = mode
+	}
+}
+
+// WithBuffer sets the capacity of the output channel
+func WithBuffer(buffer int) Option {
+	return func(o *options) {
+		o.buffer = buffer
+	}
+}
+
+type InputOption func(*input)
+
+func WithPriority(priority int) InputOption {
+	return func(i *input) {
+		i.priority = priority
+	}
+}
+
+// WithWeight is used in the WeightedFair mode, the default weight is 1
+func WithWeight(weight int) InputOption {
+	return func(i *input) {
+		i.weight = max(weight, 1)
+	}
+}
+
+type input struct {
+	id       ID
+	channel  reflect.Value
+	priority int
+	weight   int
+	current  int // for smooth weighted round robin
+}
+
+// Mux reads from dynamic set of channels into one output, the output
+// is closed when the last input is closed or the mux is closed
+type Mux[T any] struct {
+	mode     Mode
+	output   chan Message[T]
+	commands chan func()
+	done     chan struct{}
+	cancel   context.CancelFunc
+
+	// owned by the loop goroutine
+	inputs []*input
+	nextID ID
+	offset int // rotates inputs with equal priority
+}
+
+func New[T any](ctx context.Context, opts ...Option) *Mux[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Mux[T]{
+		mode:     o.mode,
+		output:   make(chan Message[T], max(o.buffer, 0)),
+		commands: make(chan func()),
+		done:     make(chan struct{}),
+		cancel:   cancel,
+	}
+
+	go m.run(ctx)
+	return m
+}
+
+func (m *Mux[T]) Out() <-chan Message[T] {
+	return m.output
+}
+
+// Done is closed when the mux stops reading inputs
+func (m *Mux[T]) Done() <-chan struct{} {
+	return m.done
+}
+
+// Close stops the mux, unread inputs are left as is
+func (m *Mux[T]) Close() {
+	m.cancel()
+	<-m.done
+}
+
+func (m *Mux[T]) execute(command func()) error {
+	finished := make(chan struct{})
+	select {
+	case m.commands <- func() { command(); close(finished) }:
+		<-finished
+		return nil
+	case <-m.done:
+		return ErrClosed
+	}
+}
+
+func (m *Mux[T]) Add(channel <-chan T, opts ...InputOption) (ID, error) {
+	in := &input{channel: reflect.ValueOf(channel), weight: 1}
+	for _, opt := range opts {
+		opt(in)
+	}
+
+	err := m.execute(func() {
+		m.nextID++
+		in.id = m.nextID
+		m.inputs = append(m.inputs, in)
+
+		// stable sorting keeps the order of addition for equal priorities
+		slices.SortStableFunc(m.inputs, func(lhs, rhs *input) int {
+			return rhs.priority - lhs.priority
+		})
+	})
+
+	return in.id, err
+}
+
+// Remove stops reading the input without closing it, the mux
+// stays open without inputs so that new ones can be added
+func (m *Mux[T]) Remove(id ID) (bool, error) {
+	var removed bool
+	err := m.execute(func() {
+		removed = m.remove(id)
+	})
+
+	return removed, err
+}
+
+func (m *Mux[T]) remove(id ID) bool {
+	index := slices.IndexFunc(m.inputs, func(in *input) bool { return in.id == id })
+	if index < 0 {
+		return false
+	}
+
+	m.inputs = slices.Delete(m.inputs, index, index+1)
+	return true
+}
+
+func (m *Mux[T]) run(ctx context.Context) {
+	defer close(m.done)
+	defer close(m.output)
+
+	for {
+		message, ok := m.next(ctx)
+		if !ok {
+			return
+		}
+
+		if !m.send(ctx, message) {
+			return
+		}
+	}
+}
+
+// send stays responsive to commands while the consumer is slow
+func (m *Mux[T]) send(ctx context.Context, message Message[T]) bool {
+	for {
+		select {
+		case m.output <- message:
+			return true
+		case command := <-m.commands:
+			command()
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (m *Mux[T]) next(ctx context.Context) (Message[T], bool) {
+	for {
+		if message, ok, closed := m.poll(); ok {
+			return message, true
+		} else if closed {
+			return Message[T]{}, false
+		}
+
+		// nothing is ready, waiting for any input
+		cases := make([]reflect.SelectCase, 0, len(m.inputs)+2)
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.commands)},
+		)
+		for _, in := range m.inputs {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: in.channel})
+		}
+
+		chosen, value, ok := reflect.Select(cases)
+		switch chosen {
+		case 0:
+			return Message[T]{}, false
+		case 1:
+			value.Interface().(func())()
+			continue
+		}
+
+		in := m.inputs[chosen-2]
+		if !ok {
+			if m.closeInput(in) {
+				return Message[T]{}, false
+			}
+			continue
+		}
+
+		m.account(in)
+		return Message[T]{Input: in.id, Value: valueOf[T](value)}, true
+	}
+}
+
+// valueOf converts a received value, nil of an interface type becomes zero T
+func valueOf[T any](value reflect.Value) T {
+	result, _ := value.Interface().(T)
+	return result
+}
+
+// closeInput reports whether it was the last input
+func (m *Mux[T]) closeInput(in *input) bool {
+	m.remove(in.id)
+	return len(m.inputs) == 0
+}
+
+// poll reads without blocking from the input chosen by the mode
+func (m *Mux[T]) poll() (Message[T], bool, bool) {
+	for _, in := range m.order() {
+		value, ok := in.channel.TryRecv()
+		if !value.IsValid() {
+			continue // would block
+		}
+
+		if !ok {
+			if m.closeInput(in) {
+				return Message[T]{}, false, true
+			}
+			continue
+		}
+
+		m.account(in)
+		return Message[T]{Input: in.id, Value: valueOf[T](value)}, true, false
+	}
+
+	return Message[T]{}, false, false
+}
+
+// order returns inputs in the order they should be checked
+func (m *Mux[T]) order() []*input {
+	ordered := slices.Clone(m.inputs)
+	if m.mode == WeightedFair {
+		// the input which is behind its share the most goes first
+		slices.SortStableFunc(ordered, func(lhs, rhs *input) int {
+			return (rhs.current + rhs.weight) - (lhs.current + lhs.weight)
+		})
+		return ordered
+	}
+
+	// inputs with the same priority are rotated, so none of them starves
+	m.offset++
+	for start := 0; start < len(ordered); {
+		end := start
+		for end < len(ordered) && ordered[end].priority == ordered[start].priority {
+			end++
+		}
+
+		group := ordered[start:end]
+		shift := m.offset % len(group)
+		slices.Reverse(group[:shift])
+		slices.Reverse(group[shift:])
+		slices.Reverse(group)
+		start = end
+	}
+
+	return ordered
+}
+
+// account applies a round of smooth weighted round robin for the chosen input
+func (m *Mux[T]) account(chosen *input) {
+	if m.mode != WeightedFair {
+		return
+	}
+
+	total := 0
+	for _, in := range m.inputs {
+		in.current += in.weight
+		total += in.weight
+	}
+
+	chosen.current -= total
+
+	// an idle input must not accumulate a burst for later
+	for _, in := range m.inputs {
+		in.current = min(in.current, total)
+	}
+}
@@ -0,0 +1,253 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func filled(values ...int) chan int {
+	channel := make(chan int, len(values))
+	for _, value := range values {
+		channel <- value
+	}
+	return channel
+}
+
+// producer keeps the channel always ready
+func producer(ctx context.Context, value int) <-chan int {
+	channel := make(chan int, 16)
+	go func() {
+		defer close(channel)
+		for {
+			select {
+			case channel <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return channel
+}
+
+func receive(t testing.TB, m *Mux[int], count int) []Message[int] {
+	var messages []Message[int]
+	for i := 0; i < count; i++ {
+		select {
+		case message, ok := <-m.Out():
+			require.True(t, ok)
+			messages = append(messages, message)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d messages", i, count)
+		}
+	}
+	return messages
+}
+
+func TestStrictPriority(t *testing.T) {
+	m := New[int](context.Background())
+	defer m.Close()
+
+	low := filled(1, 1, 1, 1, 1)
+	high := filled(2, 2, 2, 2, 2)
+	_, err := m.Add(high, WithPriority(10))
+	require.NoError(t, err)
+	_, err = m.Add(low)
+	require.NoError(t, err)
+
+	var values []int
+	for _, message := range receive(t, m, 10) {
+		values = append(values, message.Value)
+	}
+
+	assert.Equal(t, []int{2, 2, 2, 2, 2, 1, 1, 1, 1, 1}, values)
+}
+
+func TestEqualPrioritiesDontStarve(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := New[int](ctx)
+	first, _ := m.Add(producer(ctx, 1))
+	second, _ := m.Add(producer(ctx, 2))
+
+	counts := make(map[ID]int)
+	for _, message := range receive(t, m, 1000) {
+		counts[message.Input]++
+	}
+
+	assert.InDelta(t, 500, counts[first], 100)
+	assert.InDelta(t, 500, counts[second], 100)
+}
+
+func TestWeightedFair(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := New[int](ctx, WithMode(WeightedFair))
+	heavy, _ := m.Add(producer(ctx, 1), WithWeight(3))
+	light, _ := m.Add(producer(ctx, 2), WithWeight(1))
+
+	counts := make(map[ID]int)
+	for _, message := range receive(t, m, 4000) {
+		counts[message.Input]++
+	}
+
+	assert.InDelta(t, 3.0, float64(counts[heavy])/float64(counts[light]), 0.3)
+}
+
+func TestWeightedFairIdleInput(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the only ready input gets everything
+	m := New[int](ctx, WithMode(WeightedFair))
+	_, _ = m.Add(make(chan int), WithWeight(10))
+	busy, _ := m.Add(producer(ctx, 1))
+
+	for _, message := range receive(t, m, 100) {
+		assert.Equal(t, busy, message.Input)
+	}
+}
+
+func TestNilInterfaceValues(t *testing.T) {
+	m := New[error](context.Background())
+	defer m.Close()
+
+	// one value is read by polling and one while waiting
+	errs := make(chan error, 1)
+	errs <- nil
+	_, err := m.Add(errs)
+	require.NoError(t, err)
+
+	message := <-m.Out()
+	assert.Nil(t, message.Value)
+
+	errs <- nil
+	message = <-m.Out()
+	assert.Nil(t, message.Value)
+
+	expected := errors.New("failure")
+	errs <- expected
+	message = <-m.Out()
+	assert.Equal(t, expected, message.Value)
+}
+
+func TestClosesWhenInputsClose(t *testing.T) {
+	m := New[int](context.Background())
+	first := filled(1, 2)
+	second := filled(3)
+	close(first)
+	close(second)
+
+	_, err := m.Add(first)
+	require.NoError(t, err)
+	_, err = m.Add(second)
+	require.NoError(t, err)
+
+	var values []int
+	for message := range m.Out() {
+		values = append(values, message.Value)
+	}
+
+	assert.ElementsMatch(t, []int{1, 2, 3}, values)
+	<-m.Done()
+	_, err = m.Add(make(chan int))
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestDynamicInputs(t *testing.T) {
+	m := New[int](context.Background())
+	defer m.Close()
+
+	first := make(chan int)
+	id, err := m.Add(first)
+	require.NoError(t, err)
+
+	first <- 1
+	assert.Equal(t, Message[int]{Input: id, Value: 1}, receive(t, m, 1)[0])
+
+	removed, err := m.Remove(id)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = m.Remove(id)
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	// the mux without inputs waits for new ones
+	select {
+	case <-m.Done():
+		t.Fatal("mux must stay open after removal")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	second := filled(2)
+	id, err = m.Add(second)
+	require.NoError(t, err)
+	assert.Equal(t, Message[int]{Input: id, Value: 2}, receive(t, m, 1)[0])
+}
+
+func TestCloseWithPendingMessage(t *testing.T) {
+	m := New[int](context.Background())
+	_, err := m.Add(filled(1, 2, 3))
+	require.NoError(t, err)
+
+	m.Close()
+	for range m.Out() {
+	}
+
+	_, err = m.Remove(1)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+// go test -bench=. -run=^$ .
+
+func BenchmarkFairness(b *testing.B) {
+	modes := map[string]Mode{"priority": StrictPriority, "weighted": WeightedFair}
+	for name, mode := range modes {
+		b.Run(name, func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			m := New[int](ctx, WithMode(mode))
+			heavy, _ := m.Add(producer(ctx, 1), WithWeight(3), WithPriority(1))
+			_, _ = m.Add(producer(ctx, 2))
+
+			heavyCount := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if message := <-m.Out(); message.Input == heavy {
+					heavyCount++
+				}
+			}
+
+			// close to 1.0 for strict priority while the producer keeps up, 0.75 for weights 3:1
+			b.ReportMetric(float64(heavyCount)/float64(b.N), "share")
+		})
+	}
+}
+
+// plain select from prioritization_weight for comparison,
+// closed channels are always ready
+func BenchmarkSelect(b *testing.B) {
+	first, second := make(chan int), make(chan int)
+	close(first)
+	close(second)
+
+	firstCount := 0
+	for i := 0; i < b.N; i++ {
+		select {
+		case <-first:
+			firstCount++
+		case <-second:
+		}
+	}
+
+	b.ReportMetric(float64(firstCount)/float64(b.N), "share")
+}
@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %q: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline owns every goroutine started by its stages,
+// the first unhandled error cancels all of them
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+}
+
+func New(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+func (p *Pipeline) Cancel() {
+	p.cancel(context.Canceled)
+}
+
+// Wait blocks until all stages exit and returns the reason of cancellation
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+
+	err := context.Cause(p.ctx)
+	p.cancel(nil)
+	return err
+}
+
+func (p *Pipeline) fail(err error) {
+	p.cancel(err)
+}
+
+func (p *Pipeline) goroutine(action func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		action()
+	}()
+}
+
+func send[T any](ctx context.Context, ch chan<- T, value T) bool {
+	select {
+	case ch <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func receive[T any](ctx context.Context, ch <-chan T) (T, bool) {
+	select {
+	case value, ok := <-ch:
+		return value, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+func Source[T any](p *Pipeline, values ...T) <-chan T {
+	out := make(chan T)
+	p.goroutine(func() {
+		defer close(out)
+		for _, value := range values {
+			if !send(p.ctx, out, value) {
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// ForEach consumes the channel inside the pipeline, an error cancels it
+func ForEach[T any](p *Pipeline, in <-chan T, action func(T) error) {
+	p.goroutine(func() {
+		for {
+			value, ok := receive(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			if err := action(value); err != nil {
+				p.fail(err)
+				return
+			}
+		}
+	})
+}
+
+// Collect consumes the channel in the calling goroutine and waits for the pipeline
+func Collect[T any](p *Pipeline, in <-chan T) ([]T, error) {
+	var result []T
+	for {
+		value, ok := receive(p.ctx, in)
+		if !ok {
+			break
+		}
+
+		result = append(result, value)
+	}
+
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
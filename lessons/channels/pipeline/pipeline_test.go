@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -race .
+
+func numbers(count int) []int {
+	data := make([]int, count)
+	for i := range data {
+		data[i] = i
+	}
+
+	return data
+}
+
+func checkGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond * 10)
+		}
+
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	tests := map[string]struct {
+		parallelism int
+		buffer      int
+	}{
+		"sequential":          {parallelism: 1},
+		"parallel":            {parallelism: 8},
+		"parallel and buffer": {parallelism: 4, buffer: 16},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			checkGoroutines(t)
+
+			square := NewStage("square", func(_ context.Context, number int) (int, error) {
+				time.Sleep(time.Microsecond * time.Duration(rand.Intn(100)))
+				return number * number, nil
+			}, WithParallelism(test.parallelism), WithBuffer(test.buffer))
+
+			format := NewStage("format", func(_ context.Context, number int) (string, error) {
+				return strconv.Itoa(number), nil
+			}, WithParallelism(test.parallelism))
+
+			p := New(context.Background())
+			squares := Run(p, Source(p, numbers(200)...), square)
+			result, err := Collect(p, Run(p, squares, format))
+			require.NoError(t, err)
+
+			require.Len(t, result, 200)
+			for i, value := range result {
+				assert.Equal(t, strconv.Itoa(i*i), value)
+			}
+		})
+	}
+}
+
+func TestStageError(t *testing.T) {
+	checkGoroutines(t)
+	errOdd := errors.New("odd number")
+
+	var processed atomic.Int32
+	stage := NewStage("even", func(_ context.Context, number int) (int, error) {
+		processed.Add(1)
+		if number == 11 {
+			return 0, errOdd
+		}
+
+		return number, nil
+	}, WithParallelism(4))
+
+	p := New(context.Background())
+	_, err := Collect(p, Run(p, Source(p, numbers(100000)...), stage))
+
+	var stageErr *StageError
+	require.ErrorAs(t, err, &stageErr)
+	assert.Equal(t, "even", stageErr.Stage)
+	assert.ErrorIs(t, err, errOdd)
+	assert.Less(t, processed.Load(), int32(100000))
+}
+
+func TestStageErrorHandler(t *testing.T) {
+	checkGoroutines(t)
+	errOdd := errors.New("odd number")
+
+	var skipped atomic.Int32
+	stage := NewStage("even", func(_ context.Context, number int) (int, error) {
+		if number%2 != 0 {
+			return 0, errOdd
+		}
+
+		return number, nil
+	}, WithParallelism(3), WithErrorHandler(func(err error) error {
+		skipped.Add(1)
+		return nil
+	}))
+
+	p := New(context.Background())
+	result, err := Collect(p, Run(p, Source(p, numbers(10)...), stage))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, result)
+	assert.Equal(t, int32(5), skipped.Load())
+}
+
+func TestStagePanic(t *testing.T) {
+	checkGoroutines(t)
+
+	stage := NewStage("panic", func(_ context.Context, number int) (int, error) {
+		panic("boom")
+	})
+
+	p := New(context.Background())
+	_, err := Collect(p, Run(p, Source(p, 1, 2, 3), stage))
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestBackpressure(t *testing.T) {
+	checkGoroutines(t)
+
+	var processed atomic.Int32
+	stage := NewStage("count", func(_ context.Context, number int) (int, error) {
+		processed.Add(1)
+		return number, nil
+	}, WithParallelism(4), WithBuffer(2))
+
+	p := New(context.Background())
+	out := Run(p, Source(p, numbers(1000)...), stage)
+
+	<-out
+	time.Sleep(time.Millisecond * 100)
+
+	// window of parallelism + buffer items plus one item per worker blocked on results
+	assert.LessOrEqual(t, processed.Load(), int32(1+4+2+4))
+
+	p.Cancel()
+	assert.ErrorIs(t, p.Wait(), context.Canceled)
+}
+
+func TestParentCancel(t *testing.T) {
+	checkGoroutines(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stage := NewStage("slow", func(ctx context.Context, number int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, WithParallelism(2))
+
+	p := New(ctx)
+	var consumed atomic.Int32
+	ForEach(p, Run(p, Source(p, numbers(10)...), stage), func(int) error {
+		consumed.Add(1)
+		return nil
+	})
+
+	time.AfterFunc(time.Millisecond*10, cancel)
+	assert.ErrorIs(t, p.Wait(), context.Canceled)
+	assert.Equal(t, int32(0), consumed.Load())
+}
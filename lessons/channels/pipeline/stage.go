@@ -0,0 +1,180 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type stageOptions struct {
+	parallelism int
+	buffer      int
+	onError     func(error) error
+}
+
+type StageOption func(*stageOptions)
+
+func WithParallelism(parallelism int) StageOption {
+	return func(o *stageOptions) {
+		o.parallelism = parallelism
+	}
+}
+
+// WithBuffer sets the capacity of the output channel, it also lets
+// the stage work ahead of a slow item by the same number of items
+func WithBuffer(buffer int) StageOption {
+	return func(o *stageOptions) {
+		o.buffer = buffer
+	}
+}
+
+// WithErrorHandler is called for every failed item, returning nil
+// skips the item and returning an error cancels the pipeline
+func WithErrorHandler(handler func(error) error) StageOption {
+	return func(o *stageOptions) {
+		o.onError = handler
+	}
+}
+
+type Stage[In, Out any] struct {
+	name    string
+	action  func(context.Context, In) (Out, error)
+	options stageOptions
+}
+
+func NewStage[In, Out any](name string, action func(context.Context, In) (Out, error), opts ...StageOption) Stage[In, Out] {
+	o := stageOptions{parallelism: 1}
+	for _, option := range opts {
+		option(&o)
+	}
+
+	if o.parallelism <= 0 {
+		o.parallelism = 1
+	}
+
+	if o.buffer < 0 {
+		o.buffer = 0
+	}
+
+	return Stage[In, Out]{
+		name:    name,
+		action:  action,
+		options: o,
+	}
+}
+
+type job[T any] struct {
+	seq   int
+	value T
+}
+
+type result[T any] struct {
+	seq     int
+	value   T
+	skipped bool
+}
+
+// Run starts the stage: a dispatcher numbers incoming items, workers process
+// them concurrently and a reorderer emits results in the input order.
+// The number of items in flight is bounded, so a slow consumer
+// eventually blocks the dispatcher and the upstream stages
+func Run[In, Out any](p *Pipeline, in <-chan In, stage Stage[In, Out]) <-chan Out {
+	o := stage.options
+	out := make(chan Out, o.buffer)
+	jobs := make(chan job[In])
+	results := make(chan result[Out], o.parallelism)
+	window := make(chan struct{}, o.parallelism+o.buffer)
+
+	p.goroutine(func() {
+		defer close(jobs)
+		for seq := 0; ; seq++ {
+			value, ok := receive(p.ctx, in)
+			if !ok {
+				return
+			}
+
+			if !send(p.ctx, window, struct{}{}) {
+				return
+			}
+
+			if !send(p.ctx, jobs, job[In]{seq: seq, value: value}) {
+				return
+			}
+		}
+	})
+
+	workers := sync.WaitGroup{}
+	workers.Add(o.parallelism)
+	for i := 0; i < o.parallelism; i++ {
+		p.goroutine(func() {
+			defer workers.Done()
+			for j := range jobs {
+				r := result[Out]{seq: j.seq}
+
+				var err error
+				if r.value, err = stage.call(p.ctx, j.value); err != nil {
+					if o.onError != nil {
+						err = o.onError(err)
+					}
+
+					if err != nil {
+						p.fail(&StageError{Stage: stage.name, Err: err})
+						return
+					}
+
+					r.skipped = true
+				}
+
+				if !send(p.ctx, results, r) {
+					return
+				}
+			}
+		})
+	}
+
+	p.goroutine(func() {
+		workers.Wait()
+		close(results)
+	})
+
+	p.goroutine(func() {
+		defer close(out)
+
+		next := 0
+		pending := make(map[int]result[Out])
+		for {
+			r, ok := receive(p.ctx, results)
+			if !ok {
+				return
+			}
+
+			pending[r.seq] = r
+			for {
+				r, found := pending[next]
+				if !found {
+					break
+				}
+
+				if !r.skipped && !send(p.ctx, out, r.value) {
+					return
+				}
+
+				delete(pending, next)
+				<-window
+				next++
+			}
+		}
+	})
+
+	return out
+}
+
+func (s Stage[In, Out]) call(ctx context.Context, value In) (_ Out, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return s.action(ctx, value)
+}
@@ -0,0 +1,395 @@
+package bitmapindex
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+const (
+	arrayLimit   = 4096 // an array container above this size is bigger than a bitmap
+	bitmapLength = 1 << 16 / 64
+)
+
+// container stores the low 16 bits of values sharing the same high
+// 16 bits either as a sorted array (sparse) or as a bitmap (dense)
+type container struct {
+	key         uint16
+	array       []uint16
+	bitmap      []uint64
+	cardinality int
+}
+
+func (c *container) contains(low uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[low/64]&(1<<(low%64)) != 0
+	}
+
+	_, found := slices.BinarySearch(c.array, low)
+	return found
+}
+
+func (c *container) add(low uint16) {
+	if c.bitmap != nil {
+		if c.bitmap[low/64]&(1<<(low%64)) == 0 {
+			c.bitmap[low/64] |= 1 << (low % 64)
+			c.cardinality++
+		}
+		return
+	}
+
+	position, found := slices.BinarySearch(c.array, low)
+	if found {
+		return
+	}
+
+	c.array = slices.Insert(c.array, position, low)
+	c.cardinality++
+	c.normalize()
+}
+
+func (c *container) remove(low uint16) {
+	if c.bitmap != nil {
+		if c.bitmap[low/64]&(1<<(low%64)) != 0 {
+			c.bitmap[low/64] &^= 1 << (low % 64)
+			c.cardinality--
+			c.normalize()
+		}
+		return
+	}
+
+	if position, found := slices.BinarySearch(c.array, low); found {
+		c.array = slices.Delete(c.array, position, position+1)
+		c.cardinality--
+	}
+}
+
+func (c *container) normalize() {
+	if c.bitmap == nil && c.cardinality > arrayLimit {
+		c.bitmap = make([]uint64, bitmapLength)
+		for _, low := range c.array {
+			c.bitmap[low/64] |= 1 << (low % 64)
+		}
+		c.array = nil
+	} else if c.bitmap != nil && c.cardinality <= arrayLimit {
+		c.array = make([]uint16, 0, c.cardinality)
+		for i, word := range c.bitmap {
+			for word != 0 {
+				c.array = append(c.array, uint16(i*64+bits.TrailingZeros64(word)))
+				word &= word - 1
+			}
+		}
+		c.bitmap = nil
+	}
+}
+
+func (c *container) toBitmap() []uint64 {
+	if c.bitmap != nil {
+		return slices.Clone(c.bitmap)
+	}
+
+	bitmap := make([]uint64, bitmapLength)
+	for _, low := range c.array {
+		bitmap[low/64] |= 1 << (low % 64)
+	}
+
+	return bitmap
+}
+
+func fromBitmap(key uint16, bitmap []uint64) *container {
+	c := &container{key: key, bitmap: bitmap}
+	for _, word := range bitmap {
+		c.cardinality += bits.OnesCount64(word)
+	}
+
+	c.normalize()
+	return c
+}
+
+func fromArray(key uint16, array []uint16) *container {
+	c := &container{key: key, array: array, cardinality: len(array)}
+	c.normalize()
+	return c
+}
+
+func (c *container) and(other *container) *container {
+	switch {
+	case c.bitmap == nil:
+		return c.filter(other, true)
+	case other.bitmap == nil:
+		return other.filter(c, true)
+	}
+
+	bitmap := make([]uint64, bitmapLength)
+	for i := range bitmap {
+		bitmap[i] = c.bitmap[i] & other.bitmap[i]
+	}
+
+	return fromBitmap(c.key, bitmap)
+}
+
+func (c *container) or(other *container) *container {
+	if c.bitmap == nil && other.bitmap == nil && c.cardinality+other.cardinality <= arrayLimit {
+		array := make([]uint16, 0, c.cardinality+other.cardinality)
+		i, j := 0, 0
+		for i < len(c.array) && j < len(other.array) {
+			switch {
+			case c.array[i] < other.array[j]:
+				array = append(array, c.array[i])
+				i++
+			case c.array[i] > other.array[j]:
+				array = append(array, other.array[j])
+				j++
+			default:
+				array = append(array, c.array[i])
+				i++
+				j++
+			}
+		}
+
+		array = append(array, c.array[i:]...)
+		array = append(array, other.array[j:]...)
+		return fromArray(c.key, array)
+	}
+
+	bitmap := c.toBitmap()
+	if other.bitmap != nil {
+		for i := range bitmap {
+			bitmap[i] |= other.bitmap[i]
+		}
+	} else {
+		for _, low := range other.array {
+			bitmap[low/64] |= 1 << (low % 64)
+		}
+	}
+
+	return fromBitmap(c.key, bitmap)
+}
+
+func (c *container) andNot(other *container) *container {
+	if c.bitmap == nil {
+		return c.filter(other, false)
+	}
+
+	bitmap := slices.Clone(c.bitmap)
+	if other.bitmap != nil {
+		for i := range bitmap {
+			bitmap[i] &^= other.bitmap[i]
+		}
+	} else {
+		for _, low := range other.array {
+			bitmap[low/64] &^= 1 << (low % 64)
+		}
+	}
+
+	return fromBitmap(c.key, bitmap)
+}
+
+// filter keeps array values that are (or are not) contained in other
+func (c *container) filter(other *container, keep bool) *container {
+	array := make([]uint16, 0, len(c.array))
+	for _, low := range c.array {
+		if other.contains(low) == keep {
+			array = append(array, low)
+		}
+	}
+
+	return fromArray(c.key, array)
+}
+
+func (c *container) clone() *container {
+	return &container{
+		key:         c.key,
+		array:       slices.Clone(c.array),
+		bitmap:      slices.Clone(c.bitmap),
+		cardinality: c.cardinality,
+	}
+}
+
+func (c *container) sizeInBytes() int {
+	return len(c.array)*2 + len(c.bitmap)*8
+}
+
+// Bitmap is a compressed set of uint32 values in the spirit of roaring bitmaps
+type Bitmap struct {
+	containers []*container // sorted by key
+}
+
+func NewBitmap(values ...uint32) *Bitmap {
+	b := &Bitmap{}
+	for _, value := range values {
+		b.Add(value)
+	}
+
+	return b
+}
+
+func (b *Bitmap) find(key uint16) (int, bool) {
+	position := sort.Search(len(b.containers), func(i int) bool {
+		return b.containers[i].key >= key
+	})
+
+	return position, position < len(b.containers) && b.containers[position].key == key
+}
+
+func (b *Bitmap) Add(value uint32) {
+	key, low := uint16(value>>16), uint16(value)
+	position, found := b.find(key)
+	if !found {
+		b.containers = slices.Insert(b.containers, position, &container{key: key})
+	}
+
+	b.containers[position].add(low)
+}
+
+func (b *Bitmap) Remove(value uint32) {
+	key, low := uint16(value>>16), uint16(value)
+	if position, found := b.find(key); found {
+		b.containers[position].remove(low)
+		if b.containers[position].cardinality == 0 {
+			b.containers = slices.Delete(b.containers, position, position+1)
+		}
+	}
+}
+
+func (b *Bitmap) Contains(value uint32) bool {
+	position, found := b.find(uint16(value >> 16))
+	return found && b.containers[position].contains(uint16(value))
+}
+
+func (b *Bitmap) Cardinality() int {
+	cardinality := 0
+	for _, c := range b.containers {
+		cardinality += c.cardinality
+	}
+
+	return cardinality
+}
+
+func (b *Bitmap) SizeInBytes() int {
+	size := 0
+	for _, c := range b.containers {
+		size += c.sizeInBytes()
+	}
+
+	return size
+}
+
+func (b *Bitmap) All() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for _, c := range b.containers {
+			high := uint32(c.key) << 16
+			if c.bitmap == nil {
+				for _, low := range c.array {
+					if !yield(high | uint32(low)) {
+						return
+					}
+				}
+				continue
+			}
+
+			for i, word := range c.bitmap {
+				for word != 0 {
+					if !yield(high | uint32(i*64+bits.TrailingZeros64(word))) {
+						return
+					}
+					word &= word - 1
+				}
+			}
+		}
+	}
+}
+
+func (b *Bitmap) Clone() *Bitmap {
+	result := &Bitmap{containers: make([]*container, len(b.containers))}
+	for i, c := range b.containers {
+		result.containers[i] = c.clone()
+	}
+
+	return result
+}
+
+func (b *Bitmap) ToArray() []uint32 {
+	values := make([]uint32, 0, b.Cardinality())
+	for value := range b.All() {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func And(lhs, rhs *Bitmap) *Bitmap {
+	result := &Bitmap{}
+	i, j := 0, 0
+	for i < len(lhs.containers) && j < len(rhs.containers) {
+		l, r := lhs.containers[i], rhs.containers[j]
+		switch {
+		case l.key < r.key:
+			i++
+		case l.key > r.key:
+			j++
+		default:
+			if c := l.and(r); c.cardinality != 0 {
+				result.containers = append(result.containers, c)
+			}
+			i++
+			j++
+		}
+	}
+
+	return result
+}
+
+func Or(lhs, rhs *Bitmap) *Bitmap {
+	result := &Bitmap{}
+	i, j := 0, 0
+	for i < len(lhs.containers) && j < len(rhs.containers) {
+		l, r := lhs.containers[i], rhs.containers[j]
+		switch {
+		case l.key < r.key:
+			result.containers = append(result.containers, l.clone())
+			i++
+		case l.key > r.key:
+			result.containers = append(result.containers, r.clone())
+			j++
+		default:
+			result.containers = append(result.containers, l.or(r))
+			i++
+			j++
+		}
+	}
+
+	for ; i < len(lhs.containers); i++ {
+		result.containers = append(result.containers, lhs.containers[i].clone())
+	}
+
+	for ; j < len(rhs.containers); j++ {
+		result.containers = append(result.containers, rhs.containers[j].clone())
+	}
+
+	return result
+}
+
+func AndNot(lhs, rhs *Bitmap) *Bitmap {
+	result := &Bitmap{}
+	j := 0
+	for _, l := range lhs.containers {
+		for j < len(rhs.containers) && rhs.containers[j].key < l.key {
+			j++
+		}
+
+		var c *container
+		if j < len(rhs.containers) && rhs.containers[j].key == l.key {
+			c = l.andNot(rhs.containers[j])
+		} else {
+			c = l.clone()
+		}
+
+		if c.cardinality != 0 {
+			result.containers = append(result.containers, c)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,215 @@
+package bitmapindex
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -bench=. .
+
+func randomSet(random *rand.Rand, count int, limit uint32) map[uint32]struct{} {
+	set := make(map[uint32]struct{}, count)
+	for len(set) < count {
+		set[random.Uint32()%limit] = struct{}{}
+	}
+
+	return set
+}
+
+func toBitmap(set map[uint32]struct{}) *Bitmap {
+	b := NewBitmap()
+	for value := range set {
+		b.Add(value)
+	}
+
+	return b
+}
+
+func sorted(set map[uint32]struct{}, predicate func(uint32) bool) []uint32 {
+	values := make([]uint32, 0, len(set))
+	for value := range set {
+		if predicate(value) {
+			values = append(values, value)
+		}
+	}
+
+	slices.Sort(values)
+	return values
+}
+
+func TestBitmap(t *testing.T) {
+	b := NewBitmap(1, 70000, 3, 1)
+	assert.Equal(t, []uint32{1, 3, 70000}, b.ToArray())
+	assert.True(t, b.Contains(70000))
+	assert.False(t, b.Contains(70001))
+
+	b.Remove(70000)
+	b.Remove(5)
+	assert.Equal(t, []uint32{1, 3}, b.ToArray())
+	assert.Len(t, b.containers, 1)
+}
+
+func TestContainerConversion(t *testing.T) {
+	b := NewBitmap()
+	for i := uint32(0); i <= arrayLimit; i++ {
+		b.Add(i * 2)
+	}
+
+	require.NotNil(t, b.containers[0].bitmap)
+	assert.Equal(t, arrayLimit+1, b.Cardinality())
+	assert.Equal(t, bitmapLength*8, b.SizeInBytes())
+
+	b.Remove(0)
+	require.Nil(t, b.containers[0].bitmap)
+	assert.Equal(t, arrayLimit*2, b.SizeInBytes())
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := map[string]struct {
+		lhsCount int
+		rhsCount int
+		limit    uint32
+	}{
+		"sparse":         {lhsCount: 1000, rhsCount: 1000, limit: 1 << 20},
+		"dense":          {lhsCount: 30000, rhsCount: 20000, limit: 1 << 17},
+		"sparse & dense": {lhsCount: 500, rhsCount: 50000, limit: 1 << 17},
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lhsSet := randomSet(random, test.lhsCount, test.limit)
+			rhsSet := randomSet(random, test.rhsCount, test.limit)
+			lhs, rhs := toBitmap(lhsSet), toBitmap(rhsSet)
+
+			union := make(map[uint32]struct{})
+			for value := range lhsSet {
+				union[value] = struct{}{}
+			}
+			for value := range rhsSet {
+				union[value] = struct{}{}
+			}
+
+			inRhs := func(value uint32) bool { _, found := rhsSet[value]; return found }
+			assert.Equal(t, sorted(lhsSet, inRhs), And(lhs, rhs).ToArray())
+			assert.Equal(t, sorted(rhsSet, func(value uint32) bool {
+				_, found := lhsSet[value]
+				return found
+			}), And(rhs, lhs).ToArray())
+			assert.Equal(t, sorted(union, func(uint32) bool { return true }), Or(lhs, rhs).ToArray())
+			assert.Equal(t, sorted(lhsSet, func(value uint32) bool { return !inRhs(value) }), AndNot(lhs, rhs).ToArray())
+
+			assert.Equal(t, len(lhsSet), lhs.Cardinality())
+		})
+	}
+}
+
+// restaurants from lessons/data_types/bitmap_index
+func restaurants() *Index {
+	features := []string{"hookah", "pets", "veranda", "alcohol", "music"}
+	masks := []int8{
+		0b00001101,
+		0b00000010,
+		0b00010000,
+		0b00011111,
+		0b00001001,
+		0b00000101,
+	}
+
+	index := NewIndex()
+	for row, mask := range masks {
+		for bit, feature := range features {
+			if mask&(1<<bit) != 0 {
+				index.SetFlag(uint32(row), feature)
+			}
+		}
+	}
+
+	index.Set(0, "city", "Moscow")
+	index.Set(5, "city", "Saint Petersburg")
+	return index
+}
+
+func TestQuery(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		result []uint32
+	}{
+		"single flag":      {query: "hookah", result: []uint32{0, 3, 4, 5}},
+		"unknown flag":     {query: "parking", result: []uint32{}},
+		"not":              {query: "NOT hookah", result: []uint32{1, 2}},
+		"and not":          {query: "hookah AND NOT alcohol", result: []uint32{5}},
+		"precedence":       {query: "pets OR veranda AND music", result: []uint32{1, 3}},
+		"parentheses":      {query: "(pets OR veranda) AND music", result: []uint32{3}},
+		"from the request": {query: "hookah AND (pets OR veranda) AND NOT alcohol", result: []uint32{5}},
+		"lower case":       {query: "not hookah or music", result: []uint32{1, 2, 3}},
+		"value":            {query: `city = "Saint Petersburg" OR city=Moscow`, result: []uint32{0, 5}},
+		"double not":       {query: "NOT NOT pets", result: []uint32{1, 3}},
+	}
+
+	index := restaurants()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := index.Query(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	queries := []string{
+		"",
+		"hookah AND",
+		"(hookah",
+		"hookah pets",
+		"city =",
+		`city = "Moscow`,
+		"hookah & pets",
+	}
+
+	index := restaurants()
+	for _, query := range queries {
+		_, err := index.Query(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestIndexUpdates(t *testing.T) {
+	index := restaurants()
+
+	result, err := index.Evaluate("hookah")
+	require.NoError(t, err)
+	result.Add(100) // must not change the index
+
+	index.Unset(0, "hookah", "true")
+	index.Delete(3)
+
+	rows, err := index.Query("hookah")
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{4, 5}, rows)
+	assert.Equal(t, 5, index.Rows())
+}
+
+func BenchmarkQuery(b *testing.B) {
+	const rows = 1_000_000
+
+	random := rand.New(rand.NewSource(1))
+	index := NewIndex()
+	for row := uint32(0); row < rows; row++ {
+		for _, feature := range []string{"hookah", "pets", "veranda", "alcohol"} {
+			if random.Intn(4) == 0 {
+				index.SetFlag(row, feature)
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = index.Evaluate("hookah AND (pets OR veranda) AND NOT alcohol")
+	}
+}
@@ -0,0 +1,85 @@
+package bitmapindex
+
+// Index keeps one bitmap of row ids per attribute value,
+// a flag attribute is stored as the value "true"
+type Index struct {
+	rows       *Bitmap
+	attributes map[string]map[string]*Bitmap
+}
+
+func NewIndex() *Index {
+	return &Index{
+		rows:       NewBitmap(),
+		attributes: make(map[string]map[string]*Bitmap),
+	}
+}
+
+func (i *Index) Set(row uint32, attribute, value string) {
+	values, found := i.attributes[attribute]
+	if !found {
+		values = make(map[string]*Bitmap)
+		i.attributes[attribute] = values
+	}
+
+	bitmap, found := values[value]
+	if !found {
+		bitmap = NewBitmap()
+		values[value] = bitmap
+	}
+
+	bitmap.Add(row)
+	i.rows.Add(row)
+}
+
+func (i *Index) SetFlag(row uint32, attribute string) {
+	i.Set(row, attribute, "true")
+}
+
+// Unset removes the value of the attribute for the row, the row itself stays in the index
+func (i *Index) Unset(row uint32, attribute, value string) {
+	if bitmap := i.bitmap(attribute, value); bitmap != nil {
+		bitmap.Remove(row)
+	}
+}
+
+func (i *Index) Delete(row uint32) {
+	for _, values := range i.attributes {
+		for _, bitmap := range values {
+			bitmap.Remove(row)
+		}
+	}
+
+	i.rows.Remove(row)
+}
+
+func (i *Index) Rows() int {
+	return i.rows.Cardinality()
+}
+
+func (i *Index) bitmap(attribute, value string) *Bitmap {
+	return i.attributes[attribute][value]
+}
+
+// Query evaluates an expression such as `hookah AND (pets OR veranda) AND NOT alcohol`
+// or `city = "Moscow" AND NOT music` and returns matching row ids in ascending order
+func (i *Index) Query(query string) ([]uint32, error) {
+	bitmap, err := i.Evaluate(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitmap.ToArray(), nil
+}
+
+func (i *Index) Evaluate(query string) (*Bitmap, error) {
+	expression, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := expression.(termExpression); ok {
+		return expression.evaluate(i).Clone(), nil // do not expose bitmaps of the index
+	}
+
+	return expression.evaluate(i), nil
+}
@@ -0,0 +1,253 @@
+package bitmapindex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Grammar:
+//
+//	or      = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | primary
+//	primary = "(" or ")" | name [ "=" value ]
+//
+// Operators are case-insensitive, a value is a name or a quoted string
+type Expression interface {
+	evaluate(*Index) *Bitmap
+	String() string
+}
+
+type termExpression struct {
+	attribute string
+	value     string
+}
+
+func (e termExpression) evaluate(index *Index) *Bitmap {
+	if bitmap := index.bitmap(e.attribute, e.value); bitmap != nil {
+		return bitmap
+	}
+
+	return NewBitmap()
+}
+
+func (e termExpression) String() string {
+	if e.value == "true" {
+		return e.attribute
+	}
+
+	return fmt.Sprintf("%s=%q", e.attribute, e.value)
+}
+
+type notExpression struct {
+	operand Expression
+}
+
+func (e notExpression) evaluate(index *Index) *Bitmap {
+	return AndNot(index.rows, e.operand.evaluate(index))
+}
+
+func (e notExpression) String() string {
+	return "NOT " + e.operand.String()
+}
+
+type binaryExpression struct {
+	operator string
+	lhs, rhs Expression
+}
+
+func (e binaryExpression) evaluate(index *Index) *Bitmap {
+	// AND NOT is evaluated without materializing the complement
+	if not, ok := e.rhs.(notExpression); ok && e.operator == "AND" {
+		return AndNot(e.lhs.evaluate(index), not.operand.evaluate(index))
+	}
+
+	lhs, rhs := e.lhs.evaluate(index), e.rhs.evaluate(index)
+	if e.operator == "AND" {
+		return And(lhs, rhs)
+	}
+
+	return Or(lhs, rhs)
+}
+
+func (e binaryExpression) String() string {
+	return "(" + e.lhs.String() + " " + e.operator + " " + e.rhs.String() + ")"
+}
+
+type token struct {
+	kind     string // "name", "string", "(", ")", "=", "AND", "OR", "NOT", "end"
+	text     string
+	position int
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '=':
+			tokens = append(tokens, token{kind: string(r), position: i})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+
+			tokens = append(tokens, token{kind: "string", text: string(runes[i+1 : end]), position: i})
+			i = end + 1
+		case isNameRune(r):
+			end := i
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+
+			text := string(runes[i:end])
+			kind := "name"
+			if operator := strings.ToUpper(text); operator == "AND" || operator == "OR" || operator == "NOT" {
+				kind = operator
+			}
+
+			tokens = append(tokens, token{kind: kind, text: text, position: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{kind: "end", position: len(runes)}), nil
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func Parse(query string) (Expression, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	expression, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != "end" {
+		return nil, fmt.Errorf("unexpected %q at position %d", next.kind, next.position)
+	}
+
+	return expression, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != "end" {
+		p.position++
+	}
+
+	return t
+}
+
+func (p *parser) or() (Expression, error) {
+	lhs, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == "OR" {
+		p.next()
+		rhs, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		lhs = binaryExpression{operator: "OR", lhs: lhs, rhs: rhs}
+	}
+
+	return lhs, nil
+}
+
+func (p *parser) and() (Expression, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == "AND" {
+		p.next()
+		rhs, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		lhs = binaryExpression{operator: "AND", lhs: lhs, rhs: rhs}
+	}
+
+	return lhs, nil
+}
+
+func (p *parser) unary() (Expression, error) {
+	if p.peek().kind == "NOT" {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpression{operand: operand}, nil
+	}
+
+	return p.primary()
+}
+
+func (p *parser) primary() (Expression, error) {
+	t := p.next()
+	switch t.kind {
+	case "(":
+		expression, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != ")" {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.position)
+		}
+
+		return expression, nil
+	case "name":
+		if p.peek().kind != "=" {
+			return termExpression{attribute: t.text, value: "true"}, nil
+		}
+
+		p.next()
+		value := p.next()
+		if value.kind != "name" && value.kind != "string" {
+			return nil, fmt.Errorf("expected value at position %d", value.position)
+		}
+
+		return termExpression{attribute: t.text, value: value.text}, nil
+	case "end":
+		return nil, fmt.Errorf("unexpected end of query")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.kind, t.position)
+	}
+}
@@ -0,0 +1,310 @@
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	wordSize = 64
+
+	// bigger indexes in text are rejected, so a short untrusted
+	// input can't make UnmarshalText allocate a huge bitset
+	maxTextIndex = 1<<24 - 1
+)
+
+// Bitset grows automatically on Set and Flip,
+// bits beyond its length are treated as cleared
+type Bitset struct {
+	words []uint64
+}
+
+func New(length uint) *Bitset {
+	return &Bitset{
+		words: make([]uint64, (length+wordSize-1)/wordSize),
+	}
+}
+
+func From(indexes ...uint) *Bitset {
+	b := &Bitset{}
+	for _, index := range indexes {
+		b.Set(index)
+	}
+
+	return b
+}
+
+func (b *Bitset) grow(index uint) {
+	word := int(index / wordSize)
+	if word < len(b.words) {
+		return
+	}
+
+	if word < cap(b.words) {
+		b.words = b.words[:word+1]
+		return
+	}
+
+	words := make([]uint64, word+1, max(2*cap(b.words), word+1))
+	copy(words, b.words)
+	b.words = words
+}
+
+// Len returns the number of bits that can be stored without growing
+func (b *Bitset) Len() uint {
+	return uint(len(b.words)) * wordSize
+}
+
+func (b *Bitset) Set(index uint) *Bitset {
+	b.grow(index)
+	b.words[index/wordSize] |= 1 << (index % wordSize)
+	return b
+}
+
+func (b *Bitset) Clear(index uint) *Bitset {
+	if index < b.Len() {
+		b.words[index/wordSize] &^= 1 << (index % wordSize)
+	}
+
+	return b
+}
+
+func (b *Bitset) Flip(index uint) *Bitset {
+	b.grow(index)
+	b.words[index/wordSize] ^= 1 << (index % wordSize)
+	return b
+}
+
+func (b *Bitset) Test(index uint) bool {
+	if index >= b.Len() {
+		return false
+	}
+
+	return b.words[index/wordSize]&(1<<(index%wordSize)) != 0
+}
+
+func (b *Bitset) Count() int {
+	count := 0
+	for _, word := range b.words {
+		count += bits.OnesCount64(word)
+	}
+
+	return count
+}
+
+func (b *Bitset) Any() bool {
+	for _, word := range b.words {
+		if word != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextSet returns the first set bit at or after index
+func (b *Bitset) NextSet(index uint) (uint, bool) {
+	word := int(index / wordSize)
+	if word >= len(b.words) {
+		return 0, false
+	}
+
+	current := b.words[word] >> (index % wordSize)
+	if current != 0 {
+		return index + uint(bits.TrailingZeros64(current)), true
+	}
+
+	for word++; word < len(b.words); word++ {
+		if b.words[word] != 0 {
+			return uint(word)*wordSize + uint(bits.TrailingZeros64(b.words[word])), true
+		}
+	}
+
+	return 0, false
+}
+
+// NextClear returns the first cleared bit at or after index,
+// it always exists because the bitset is unbounded
+func (b *Bitset) NextClear(index uint) uint {
+	word := int(index / wordSize)
+	if word >= len(b.words) {
+		return index
+	}
+
+	current := ^b.words[word] >> (index % wordSize)
+	if current != 0 {
+		return index + uint(bits.TrailingZeros64(current))
+	}
+
+	for word++; word < len(b.words); word++ {
+		if b.words[word] != ^uint64(0) {
+			return uint(word)*wordSize + uint(bits.TrailingZeros64(^b.words[word]))
+		}
+	}
+
+	return b.Len()
+}
+
+func (b *Bitset) All() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for i, word := range b.words {
+			for word != 0 {
+				index := uint(i)*wordSize + uint(bits.TrailingZeros64(word))
+				if !yield(index) {
+					return
+				}
+
+				word &= word - 1 // reset the lowest set bit
+			}
+		}
+	}
+}
+
+func (b *Bitset) And(other *Bitset) *Bitset {
+	for i := range b.words {
+		if i < len(other.words) {
+			b.words[i] &= other.words[i]
+		} else {
+			b.words[i] = 0
+		}
+	}
+
+	return b
+}
+
+func (b *Bitset) Or(other *Bitset) *Bitset {
+	if len(other.words) > 0 {
+		b.grow(uint(len(other.words))*wordSize - 1)
+	}
+
+	for i, word := range other.words {
+		b.words[i] |= word
+	}
+
+	return b
+}
+
+func (b *Bitset) Xor(other *Bitset) *Bitset {
+	if len(other.words) > 0 {
+		b.grow(uint(len(other.words))*wordSize - 1)
+	}
+
+	for i, word := range other.words {
+		b.words[i] ^= word
+	}
+
+	return b
+}
+
+func (b *Bitset) AndNot(other *Bitset) *Bitset {
+	for i := range min(len(b.words), len(other.words)) {
+		b.words[i] &^= other.words[i]
+	}
+
+	return b
+}
+
+func (b *Bitset) Clone() *Bitset {
+	words := make([]uint64, len(b.words))
+	copy(words, b.words)
+	return &Bitset{words: words}
+}
+
+// Equal ignores the capacity, only set bits are compared
+func (b *Bitset) Equal(other *Bitset) bool {
+	lhs, rhs := b.trimmed(), other.trimmed()
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	for i := range lhs {
+		if lhs[i] != rhs[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Bitset) trimmed() []uint64 {
+	words := b.words
+	for len(words) > 0 && words[len(words)-1] == 0 {
+		words = words[:len(words)-1]
+	}
+
+	return words
+}
+
+// MarshalBinary stores words in little endian order without trailing zero words
+func (b *Bitset) MarshalBinary() ([]byte, error) {
+	words := b.trimmed()
+	data := make([]byte, 0, len(words)*8)
+	for _, word := range words {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+
+	return data, nil
+}
+
+func (b *Bitset) UnmarshalBinary(data []byte) error {
+	if len(data)%8 != 0 {
+		return errors.New("bitset: binary length is not a multiple of 8")
+	}
+
+	b.words = make([]uint64, len(data)/8)
+	for i := range b.words {
+		b.words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+
+	return nil
+}
+
+// MarshalText lists set bits: {1,3,5}
+func (b *Bitset) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *Bitset) UnmarshalText(text []byte) error {
+	str := string(text)
+	if !strings.HasPrefix(str, "{") || !strings.HasSuffix(str, "}") {
+		return errors.New("bitset: text must be enclosed in braces")
+	}
+
+	result := Bitset{}
+	if str = str[1 : len(str)-1]; str != "" {
+		for _, part := range strings.Split(str, ",") {
+			index, err := strconv.ParseUint(strings.TrimSpace(part), 10, 0)
+			if err != nil {
+				return errors.New("bitset: invalid index " + strconv.Quote(part))
+			}
+
+			if index > maxTextIndex {
+				return errors.New("bitset: index " + strconv.Quote(part) + " is too big")
+			}
+
+			result.Set(uint(index))
+		}
+	}
+
+	*b = result
+	return nil
+}
+
+func (b *Bitset) String() string {
+	builder := strings.Builder{}
+	builder.WriteByte('{')
+	for index := range b.All() {
+		if builder.Len() > 1 {
+			builder.WriteByte(',')
+		}
+
+		builder.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+
+	builder.WriteByte('}')
+	return builder.String()
+}
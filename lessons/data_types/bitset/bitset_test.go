@@ -0,0 +1,141 @@
+package bitset
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -bench=. .
+
+func TestOperations(t *testing.T) {
+	b := New(10)
+	assert.Equal(t, uint(64), b.Len())
+
+	b.Set(1).Set(3).Set(200)
+	assert.True(t, b.Test(1))
+	assert.True(t, b.Test(200))
+	assert.False(t, b.Test(2))
+	assert.False(t, b.Test(10000))
+	assert.Equal(t, 3, b.Count())
+	assert.Equal(t, uint(256), b.Len())
+
+	b.Clear(3).Clear(100000).Flip(1).Flip(2)
+	assert.Equal(t, []uint{2, 200}, slices.Collect(b.All()))
+	assert.True(t, b.Any())
+	assert.False(t, New(100).Any())
+}
+
+func TestNext(t *testing.T) {
+	b := From(0, 1, 2, 63, 64, 130)
+
+	tests := map[string]struct {
+		index     uint
+		nextSet   uint
+		found     bool
+		nextClear uint
+	}{
+		"start":          {index: 0, nextSet: 0, found: true, nextClear: 3},
+		"inside word":    {index: 3, nextSet: 63, found: true, nextClear: 3},
+		"word boundary":  {index: 63, nextSet: 63, found: true, nextClear: 65},
+		"next word":      {index: 65, nextSet: 130, found: true, nextClear: 65},
+		"after last set": {index: 131, found: false, nextClear: 131},
+		"beyond length":  {index: 1000, found: false, nextClear: 1000},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			next, found := b.NextSet(test.index)
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.nextSet, next)
+			assert.Equal(t, test.nextClear, b.NextClear(test.index))
+		})
+	}
+
+	full := New(128)
+	for i := uint(0); i < 128; i++ {
+		full.Set(i)
+	}
+
+	assert.Equal(t, uint(128), full.NextClear(5))
+}
+
+func TestSetOperations(t *testing.T) {
+	lhs := func() *Bitset { return From(1, 2, 3, 100) }
+	rhs := From(2, 3, 4, 300)
+
+	assert.Equal(t, "{2,3}", lhs().And(rhs).String())
+	assert.Equal(t, "{1,2,3,4,100,300}", lhs().Or(rhs).String())
+	assert.Equal(t, "{1,4,100,300}", lhs().Xor(rhs).String())
+	assert.Equal(t, "{1,100}", lhs().AndNot(rhs).String())
+	assert.Equal(t, "{2,3}", rhs.Clone().And(lhs()).String())
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, New(1000).Set(5).Equal(From(5)))
+	assert.False(t, From(5).Equal(From(5, 6)))
+	assert.True(t, From(5, 600).Clear(600).Equal(From(5)))
+}
+
+func TestMarshalling(t *testing.T) {
+	b := From(0, 7, 64, 1000)
+
+	data, err := b.MarshalBinary()
+	require.NoError(t, err)
+
+	var binaryResult Bitset
+	require.NoError(t, binaryResult.UnmarshalBinary(data))
+	assert.True(t, b.Equal(&binaryResult))
+
+	text, err := json.Marshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, `"{0,7,64,1000}"`, string(text))
+
+	var textResult Bitset
+	require.NoError(t, json.Unmarshal(text, &textResult))
+	assert.True(t, b.Equal(&textResult))
+
+	assert.Error(t, textResult.UnmarshalText([]byte("{1,x}")))
+	assert.Error(t, textResult.UnmarshalText([]byte("1,2")))
+	assert.NoError(t, textResult.UnmarshalText([]byte("{}")))
+	assert.False(t, textResult.Any())
+	assert.Error(t, textResult.UnmarshalText([]byte("{18446744073709551615}")))
+	assert.Error(t, textResult.UnmarshalText([]byte("{16777216}")))
+	require.NoError(t, textResult.UnmarshalText([]byte("{16777215}")))
+	assert.True(t, textResult.Test(maxTextIndex))
+	assert.Error(t, binaryResult.UnmarshalBinary([]byte{1, 2, 3}))
+}
+
+var Result int
+
+func BenchmarkCount(b *testing.B) {
+	bitset := New(1 << 16)
+	for i := uint(0); i < 1<<16; i += 3 {
+		bitset.Set(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result = bitset.Count()
+	}
+}
+
+func BenchmarkIteration(b *testing.B) {
+	bitset := New(1 << 16)
+	for i := uint(0); i < 1<<16; i += 3 {
+		bitset.Set(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count := 0
+		for range bitset.All() {
+			count++
+		}
+
+		Result = count
+	}
+}
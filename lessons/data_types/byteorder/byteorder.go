@@ -0,0 +1,150 @@
+package byteorder
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+type Unsigned interface {
+	~uint16 | ~uint32 | ~uint64
+}
+
+func Swap[T Unsigned](number T) T {
+	switch unsafe.Sizeof(number) {
+	case 2:
+		return T(bits.ReverseBytes16(uint16(number)))
+	case 4:
+		return T(bits.ReverseBytes32(uint32(number)))
+	default:
+		return T(bits.ReverseBytes64(uint64(number)))
+	}
+}
+
+type ByteOrder interface {
+	Uint16([]byte) uint16
+	Uint32([]byte) uint32
+	Uint64([]byte) uint64
+	PutUint16([]byte, uint16)
+	PutUint32([]byte, uint32)
+	PutUint64([]byte, uint64)
+	String() string
+}
+
+var (
+	LittleEndian littleEndian
+	BigEndian    bigEndian
+	Host         = hostOrder()
+)
+
+func IsLittleEndian() bool {
+	var number uint16 = 0x0001
+	return *(*uint8)(unsafe.Pointer(&number)) == 1
+}
+
+func IsBigEndian() bool {
+	return !IsLittleEndian()
+}
+
+func hostOrder() ByteOrder {
+	if IsLittleEndian() {
+		return LittleEndian
+	}
+
+	return BigEndian
+}
+
+type littleEndian struct{}
+
+func (littleEndian) Uint16(b []byte) uint16 {
+	_ = b[1] // bounds check elimination
+	return uint16(b[0]) | uint16(b[1])<<8
+}
+
+func (littleEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func (littleEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+		uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56
+}
+
+func (littleEndian) PutUint16(b []byte, number uint16) {
+	_ = b[1]
+	b[0] = byte(number)
+	b[1] = byte(number >> 8)
+}
+
+func (littleEndian) PutUint32(b []byte, number uint32) {
+	_ = b[3]
+	b[0] = byte(number)
+	b[1] = byte(number >> 8)
+	b[2] = byte(number >> 16)
+	b[3] = byte(number >> 24)
+}
+
+func (littleEndian) PutUint64(b []byte, number uint64) {
+	_ = b[7]
+	b[0] = byte(number)
+	b[1] = byte(number >> 8)
+	b[2] = byte(number >> 16)
+	b[3] = byte(number >> 24)
+	b[4] = byte(number >> 32)
+	b[5] = byte(number >> 40)
+	b[6] = byte(number >> 48)
+	b[7] = byte(number >> 56)
+}
+
+func (littleEndian) String() string {
+	return "LittleEndian"
+}
+
+type bigEndian struct{}
+
+func (bigEndian) Uint16(b []byte) uint16 {
+	_ = b[1]
+	return uint16(b[1]) | uint16(b[0])<<8
+}
+
+func (bigEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[3]) | uint32(b[2])<<8 | uint32(b[1])<<16 | uint32(b[0])<<24
+}
+
+func (bigEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(b[7]) | uint64(b[6])<<8 | uint64(b[5])<<16 | uint64(b[4])<<24 |
+		uint64(b[3])<<32 | uint64(b[2])<<40 | uint64(b[1])<<48 | uint64(b[0])<<56
+}
+
+func (bigEndian) PutUint16(b []byte, number uint16) {
+	_ = b[1]
+	b[0] = byte(number >> 8)
+	b[1] = byte(number)
+}
+
+func (bigEndian) PutUint32(b []byte, number uint32) {
+	_ = b[3]
+	b[0] = byte(number >> 24)
+	b[1] = byte(number >> 16)
+	b[2] = byte(number >> 8)
+	b[3] = byte(number)
+}
+
+func (bigEndian) PutUint64(b []byte, number uint64) {
+	_ = b[7]
+	b[0] = byte(number >> 56)
+	b[1] = byte(number >> 48)
+	b[2] = byte(number >> 40)
+	b[3] = byte(number >> 32)
+	b[4] = byte(number >> 24)
+	b[5] = byte(number >> 16)
+	b[6] = byte(number >> 8)
+	b[7] = byte(number)
+}
+
+func (bigEndian) String() string {
+	return "BigEndian"
+}
@@ -0,0 +1,240 @@
+package byteorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -bench=. .
+
+func TestSwap(t *testing.T) {
+	assert.Equal(t, uint16(0x0201), Swap(uint16(0x0102)))
+	assert.Equal(t, uint32(0x04030201), Swap(uint32(0x01020304)))
+	assert.Equal(t, uint64(0x0807060504030201), Swap(uint64(0x0102030405060708)))
+
+	type Port uint16
+	assert.Equal(t, Port(0x5000), Swap(Port(80)))
+}
+
+func TestHostOrder(t *testing.T) {
+	buffer := make([]byte, 4)
+	binary.NativeEndian.PutUint32(buffer, 0x01020304)
+	assert.Equal(t, uint32(0x01020304), Host.Uint32(buffer))
+	assert.NotEqual(t, IsLittleEndian(), IsBigEndian())
+}
+
+func TestByteOrder(t *testing.T) {
+	tests := map[string]struct {
+		order    ByteOrder
+		standard binary.ByteOrder
+	}{
+		"little endian": {order: LittleEndian, standard: binary.LittleEndian},
+		"big endian":    {order: BigEndian, standard: binary.BigEndian},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			buffer := make([]byte, 8)
+			expected := make([]byte, 8)
+
+			test.order.PutUint16(buffer, 0xABCD)
+			test.standard.PutUint16(expected, 0xABCD)
+			assert.Equal(t, expected, buffer)
+			assert.Equal(t, uint16(0xABCD), test.order.Uint16(buffer))
+
+			test.order.PutUint32(buffer, 0x01020304)
+			test.standard.PutUint32(expected, 0x01020304)
+			assert.Equal(t, expected, buffer)
+			assert.Equal(t, uint32(0x01020304), test.order.Uint32(buffer))
+
+			test.order.PutUint64(buffer, 0x0102030405060708)
+			test.standard.PutUint64(expected, 0x0102030405060708)
+			assert.Equal(t, expected, buffer)
+			assert.Equal(t, uint64(0x0102030405060708), test.order.Uint64(buffer))
+		})
+	}
+}
+
+func TestVarint(t *testing.T) {
+	numbers := []int64{0, 1, -1, 63, -64, 64, 300, -300, math.MaxInt64, math.MinInt64}
+	for _, number := range numbers {
+		buffer := AppendVarint(nil, number)
+		assert.Equal(t, binary.AppendVarint(nil, number), buffer)
+
+		decoded, n, err := Varint(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, number, decoded)
+		assert.Equal(t, len(buffer), n)
+	}
+
+	assert.Equal(t, uint64(3), ZigZagEncode(-2))
+	assert.Equal(t, int64(-2), ZigZagDecode(3))
+}
+
+func TestUvarintErrors(t *testing.T) {
+	_, err := PutUvarint(make([]byte, 1), 300)
+	assert.ErrorIs(t, err, ErrBufferTooSmall)
+
+	_, _, err = Uvarint([]byte{0x80, 0x80})
+	assert.ErrorIs(t, err, ErrBufferTooSmall)
+
+	overflow := bytes.Repeat([]byte{0xFF}, MaxVarintLen64-1)
+	_, _, err = Uvarint(append(overflow, 0x02))
+	assert.ErrorIs(t, err, ErrVarintOverflow)
+
+	number, n, err := Uvarint(append(overflow, 0x01))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), number)
+	assert.Equal(t, MaxVarintLen64, n)
+}
+
+type Header struct {
+	Magic    [4]byte
+	Version  uint16
+	Flags    uint8
+	_        uint8
+	Length   int32
+	Checksum uint64
+	Scale    float32
+	Valid    bool
+	Point    struct{ X, Y int16 }
+}
+
+func TestCodec(t *testing.T) {
+	header := Header{
+		Magic:    [4]byte{'G', 'O', 'P', 'H'},
+		Version:  2,
+		Flags:    0x81,
+		Length:   -42,
+		Checksum: 0xDEADBEEF,
+		Scale:    1.5,
+		Valid:    true,
+	}
+	header.Point.X, header.Point.Y = -1, 7
+
+	for _, order := range []ByteOrder{LittleEndian, BigEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			var standard binary.ByteOrder = binary.LittleEndian
+			if order == ByteOrder(BigEndian) {
+				standard = binary.BigEndian
+			}
+
+			buffer, err := Encode(order, &header)
+			require.NoError(t, err)
+
+			expected, err := binary.Append(nil, standard, header)
+			require.NoError(t, err)
+			assert.Equal(t, expected, buffer)
+
+			var decoded Header
+			n, err := Decode(order, buffer, &decoded)
+			require.NoError(t, err)
+			assert.Equal(t, len(buffer), n)
+			assert.Equal(t, header, decoded)
+		})
+	}
+}
+
+func TestCodecErrors(t *testing.T) {
+	_, err := Encode(LittleEndian, struct{ Name string }{})
+	assert.ErrorIs(t, err, ErrNotFixedSize)
+
+	var header Header
+	_, err = Decode(LittleEndian, make([]byte, 3), &header)
+	assert.ErrorIs(t, err, ErrBufferTooSmall)
+
+	_, err = Decode(LittleEndian, make([]byte, 32), header)
+	assert.Error(t, err)
+
+	_, err = Encode(LittleEndian, nil)
+	assert.ErrorIs(t, err, ErrNilValue)
+	_, err = Encode(LittleEndian, (*Header)(nil))
+	assert.ErrorIs(t, err, ErrNilValue)
+	_, err = Size((*Header)(nil))
+	assert.ErrorIs(t, err, ErrNilValue)
+}
+
+type inner struct {
+	Value uint16
+}
+
+func TestDecodeUnexportedFields(t *testing.T) {
+	var hidden struct {
+		Version uint8
+		length  uint32
+	}
+	_, err := Decode(LittleEndian, make([]byte, 5), &hidden)
+	assert.ErrorIs(t, err, ErrUnexportedField)
+
+	var nested struct {
+		Items [2]struct{ value uint8 }
+	}
+	_, err = Decode(LittleEndian, make([]byte, 2), &nested)
+	assert.ErrorIs(t, err, ErrUnexportedField)
+
+	var embedded struct {
+		inner
+		_ uint8
+	}
+	n, err := Decode(LittleEndian, []byte{1, 2, 3}, &embedded)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, uint16(0x0201), embedded.Value)
+}
+
+var Result uint64
+
+func BenchmarkPutUint64(b *testing.B) {
+	buffer := make([]byte, 8)
+	for i := 0; i < b.N; i++ {
+		BigEndian.PutUint64(buffer, uint64(i))
+		Result = BigEndian.Uint64(buffer)
+	}
+}
+
+func BenchmarkBinaryPutUint64(b *testing.B) {
+	buffer := make([]byte, 8)
+	for i := 0; i < b.N; i++ {
+		binary.BigEndian.PutUint64(buffer, uint64(i))
+		Result = binary.BigEndian.Uint64(buffer)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	header := Header{Version: 1, Length: 100}
+	for i := 0; i < b.N; i++ {
+		buffer, _ := Encode(BigEndian, &header)
+		Result = uint64(len(buffer))
+	}
+}
+
+func BenchmarkBinaryEncode(b *testing.B) {
+	header := Header{Version: 1, Length: 100}
+	for i := 0; i < b.N; i++ {
+		buffer, _ := binary.Append(nil, binary.BigEndian, &header)
+		Result = uint64(len(buffer))
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	buffer, _ := Encode(BigEndian, &Header{Version: 1, Length: 100})
+
+	var header Header
+	for i := 0; i < b.N; i++ {
+		_, _ = Decode(BigEndian, buffer, &header)
+	}
+}
+
+func BenchmarkBinaryDecode(b *testing.B) {
+	buffer, _ := Encode(BigEndian, &Header{Version: 1, Length: 100})
+
+	var header Header
+	for i := 0; i < b.N; i++ {
+		_, _ = binary.Decode(buffer, binary.BigEndian, &header)
+	}
+}
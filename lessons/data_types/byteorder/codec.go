@@ -0,0 +1,261 @@
+package byteorder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+var (
+	ErrNotFixedSize    = errors.New("type has no fixed size")
+	ErrNilValue        = errors.New("value is nil")
+	ErrUnexportedField = errors.New("unexported fields can't be decoded")
+)
+
+var sizes sync.Map // reflect.Type -> int
+
+// Size returns the encoded size of fixed-size values: booleans, numbers,
+// arrays and structs of them. Blank (_) struct fields are encoded as zeros
+func Size(value any) (int, error) {
+	v, err := indirect(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return sizeOf(v.Type())
+}
+
+func indirect(value any) (reflect.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return reflect.Value{}, ErrNilValue
+	}
+
+	return v, nil
+}
+
+func sizeOf(t reflect.Type) (int, error) {
+	if size, found := sizes.Load(t); found {
+		return size.(int), nil
+	}
+
+	size := 0
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		size = 1
+	case reflect.Int16, reflect.Uint16:
+		size = 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		size = 4
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		size = 8
+	case reflect.Array:
+		elemSize, err := sizeOf(t.Elem())
+		if err != nil {
+			return 0, err
+		}
+
+		size = elemSize * t.Len()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			fieldSize, err := sizeOf(t.Field(i).Type)
+			if err != nil {
+				return 0, err
+			}
+
+			size += fieldSize
+		}
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrNotFixedSize, t)
+	}
+
+	sizes.Store(t, size)
+	return size, nil
+}
+
+func Encode(order ByteOrder, value any) ([]byte, error) {
+	v, err := indirect(value)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := sizeOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, size)
+	encode(order, buffer, v)
+	return buffer, nil
+}
+
+// Decode fills the value pointed to by pointer and returns the count of bytes read
+func Decode(order ByteOrder, buffer []byte, pointer any) (int, error) {
+	v := reflect.ValueOf(pointer)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0, errors.New("decode requires a non-nil pointer")
+	}
+
+	v = v.Elem()
+	size, err := sizeOf(v.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkSettable(v.Type()); err != nil {
+		return 0, err
+	}
+
+	if len(buffer) < size {
+		return 0, ErrBufferTooSmall
+	}
+
+	decode(order, buffer, v)
+	return size, nil
+}
+
+// checkSettable finds fields which reflection can't set, exported
+// fields of embedded unexported structs are still settable
+func checkSettable(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Array:
+		return checkSettable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Name == "_" {
+				continue
+			}
+
+			if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+				return fmt.Errorf("%w: %s.%s", ErrUnexportedField, t, field.Name)
+			}
+
+			if err := checkSettable(field.Type); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func encode(order ByteOrder, buffer []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Bool:
+		buffer[0] = 0
+		if v.Bool() {
+			buffer[0] = 1
+		}
+		return buffer[1:]
+	case reflect.Int8:
+		buffer[0] = byte(v.Int())
+		return buffer[1:]
+	case reflect.Uint8:
+		buffer[0] = byte(v.Uint())
+		return buffer[1:]
+	case reflect.Int16:
+		order.PutUint16(buffer, uint16(v.Int()))
+		return buffer[2:]
+	case reflect.Uint16:
+		order.PutUint16(buffer, uint16(v.Uint()))
+		return buffer[2:]
+	case reflect.Int32:
+		order.PutUint32(buffer, uint32(v.Int()))
+		return buffer[4:]
+	case reflect.Uint32:
+		order.PutUint32(buffer, uint32(v.Uint()))
+		return buffer[4:]
+	case reflect.Float32:
+		order.PutUint32(buffer, math.Float32bits(float32(v.Float())))
+		return buffer[4:]
+	case reflect.Int64:
+		order.PutUint64(buffer, uint64(v.Int()))
+		return buffer[8:]
+	case reflect.Uint64:
+		order.PutUint64(buffer, v.Uint())
+		return buffer[8:]
+	case reflect.Float64:
+		order.PutUint64(buffer, math.Float64bits(v.Float()))
+		return buffer[8:]
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			buffer = encode(order, buffer, v.Index(i))
+		}
+		return buffer
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).Name == "_" {
+				size, _ := sizeOf(t.Field(i).Type)
+				clear(buffer[:size])
+				buffer = buffer[size:]
+				continue
+			}
+
+			buffer = encode(order, buffer, v.Field(i))
+		}
+		return buffer
+	default:
+		panic("unreachable: size is checked before encoding")
+	}
+}
+
+func decode(order ByteOrder, buffer []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(buffer[0] != 0)
+		return buffer[1:]
+	case reflect.Int8:
+		v.SetInt(int64(int8(buffer[0])))
+		return buffer[1:]
+	case reflect.Uint8:
+		v.SetUint(uint64(buffer[0]))
+		return buffer[1:]
+	case reflect.Int16:
+		v.SetInt(int64(int16(order.Uint16(buffer))))
+		return buffer[2:]
+	case reflect.Uint16:
+		v.SetUint(uint64(order.Uint16(buffer)))
+		return buffer[2:]
+	case reflect.Int32:
+		v.SetInt(int64(int32(order.Uint32(buffer))))
+		return buffer[4:]
+	case reflect.Uint32:
+		v.SetUint(uint64(order.Uint32(buffer)))
+		return buffer[4:]
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(order.Uint32(buffer))))
+		return buffer[4:]
+	case reflect.Int64:
+		v.SetInt(int64(order.Uint64(buffer)))
+		return buffer[8:]
+	case reflect.Uint64:
+		v.SetUint(order.Uint64(buffer))
+		return buffer[8:]
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(order.Uint64(buffer)))
+		return buffer[8:]
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			buffer = decode(order, buffer, v.Index(i))
+		}
+		return buffer
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).Name == "_" {
+				size, _ := sizeOf(t.Field(i).Type)
+				buffer = buffer[size:]
+				continue
+			}
+
+			buffer = decode(order, buffer, v.Field(i))
+		}
+		return buffer
+	default:
+		panic("unreachable: size is checked before decoding")
+	}
+}
@@ -0,0 +1,86 @@
+package byteorder
+
+import "errors"
+
+const MaxVarintLen64 = 10
+
+var (
+	ErrBufferTooSmall = errors.New("buffer too small")
+	ErrVarintOverflow = errors.New("varint overflows a 64-bit integer")
+)
+
+// ZigZagEncode maps signed integers to unsigned ones so that numbers
+// with a small absolute value have a short varint: 0, -1, 1, -2 -> 0, 1, 2, 3
+func ZigZagEncode(number int64) uint64 {
+	return uint64(number<<1) ^ uint64(number>>63)
+}
+
+func ZigZagDecode(number uint64) int64 {
+	return int64(number>>1) ^ -int64(number&1)
+}
+
+func AppendUvarint(buffer []byte, number uint64) []byte {
+	for number >= 0x80 {
+		buffer = append(buffer, byte(number)|0x80)
+		number >>= 7
+	}
+
+	return append(buffer, byte(number))
+}
+
+func PutUvarint(buffer []byte, number uint64) (int, error) {
+	i := 0
+	for number >= 0x80 {
+		if i == len(buffer) {
+			return 0, ErrBufferTooSmall
+		}
+
+		buffer[i] = byte(number) | 0x80
+		number >>= 7
+		i++
+	}
+
+	if i == len(buffer) {
+		return 0, ErrBufferTooSmall
+	}
+
+	buffer[i] = byte(number)
+	return i + 1, nil
+}
+
+// Uvarint returns the decoded number and the count of bytes read
+func Uvarint(buffer []byte) (uint64, int, error) {
+	var number uint64
+	var shift uint
+	for i, b := range buffer {
+		if i == MaxVarintLen64 {
+			return 0, 0, ErrVarintOverflow
+		}
+
+		if b < 0x80 {
+			if i == MaxVarintLen64-1 && b > 1 {
+				return 0, 0, ErrVarintOverflow
+			}
+
+			return number | uint64(b)<<shift, i + 1, nil
+		}
+
+		number |= uint64(b&0x7F) << shift
+		shift += 7
+	}
+
+	return 0, 0, ErrBufferTooSmall
+}
+
+func AppendVarint(buffer []byte, number int64) []byte {
+	return AppendUvarint(buffer, ZigZagEncode(number))
+}
+
+func PutVarint(buffer []byte, number int64) (int, error) {
+	return PutUvarint(buffer, ZigZagEncode(number))
+}
+
+func Varint(buffer []byte) (int64, int, error) {
+	number, n, err := Uvarint(buffer)
+	return ZigZagDecode(number), n, err
+}
@@ -0,0 +1,145 @@
+package checked
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var (
+	ErrIntOverflow    = errors.New("integer overflow")
+	ErrDivisionByZero = errors.New("integer division by zero")
+)
+
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type Integer interface {
+	Signed | Unsigned
+}
+
+func signed[T Integer]() bool {
+	var zero T
+	return ^zero < 0
+}
+
+func bitsOf[T Integer]() uint {
+	var zero T
+	return uint(unsafe.Sizeof(zero)) * 8
+}
+
+func Min[T Integer]() T {
+	if !signed[T]() {
+		return 0
+	}
+
+	return T(1) << (bitsOf[T]() - 1)
+}
+
+func Max[T Integer]() T {
+	return ^Min[T]()
+}
+
+func Add[T Integer](lhs, rhs T) (T, error) {
+	result := lhs + rhs
+	if (rhs > 0 && result < lhs) || (rhs < 0 && result > lhs) {
+		return 0, ErrIntOverflow
+	}
+
+	return result, nil
+}
+
+func Sub[T Integer](lhs, rhs T) (T, error) {
+	result := lhs - rhs
+	if (rhs > 0 && result > lhs) || (rhs < 0 && result < lhs) {
+		return 0, ErrIntOverflow
+	}
+
+	return result, nil
+}
+
+func Mul[T Integer](lhs, rhs T) (T, error) {
+	if lhs == 0 || rhs == 0 {
+		return 0, nil
+	}
+
+	if signed[T]() && ((lhs == ^T(0) && rhs == Min[T]()) || (rhs == ^T(0) && lhs == Min[T]())) {
+		return 0, ErrIntOverflow
+	}
+
+	result := lhs * rhs
+	if result/rhs != lhs {
+		return 0, ErrIntOverflow
+	}
+
+	return result, nil
+}
+
+func Div[T Integer](lhs, rhs T) (T, error) {
+	if rhs == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	if signed[T]() && lhs == Min[T]() && rhs == ^T(0) {
+		return 0, ErrIntOverflow
+	}
+
+	return lhs / rhs, nil
+}
+
+func Neg[T Integer](number T) (T, error) {
+	if signed[T]() && number == Min[T]() {
+		return 0, ErrIntOverflow
+	}
+
+	if !signed[T]() && number != 0 {
+		return 0, ErrIntOverflow
+	}
+
+	return -number, nil
+}
+
+func Abs[T Integer](number T) (T, error) {
+	if number >= 0 {
+		return number, nil
+	}
+
+	return Neg(number)
+}
+
+// Shl fails when a set bit (or the sign) is shifted out
+func Shl[T Integer](number T, shift uint) (T, error) {
+	if number == 0 {
+		return 0, nil
+	}
+
+	if shift >= bitsOf[T]() {
+		return 0, ErrIntOverflow
+	}
+
+	result := number << shift
+	if result>>shift != number || (result < 0) != (number < 0) {
+		return 0, ErrIntOverflow
+	}
+
+	return result, nil
+}
+
+// Shr never overflows, it is arithmetic for signed types
+func Shr[T Integer](number T, shift uint) T {
+	return number >> shift
+}
+
+// Cast converts between integer types and fails when the value is not representable
+func Cast[To, From Integer](number From) (To, error) {
+	result := To(number)
+	if From(result) != number || (result < 0) != (number < 0) {
+		return 0, ErrIntOverflow
+	}
+
+	return result, nil
+}
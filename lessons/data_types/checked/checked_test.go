@@ -0,0 +1,241 @@
+package checked
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v .
+// go test -fuzz=FuzzInt64 .
+
+func TestLimits(t *testing.T) {
+	assert.Equal(t, int8(math.MinInt8), Min[int8]())
+	assert.Equal(t, int8(math.MaxInt8), Max[int8]())
+	assert.Equal(t, int64(math.MinInt64), Min[int64]())
+	assert.Equal(t, uint16(0), Min[uint16]())
+	assert.Equal(t, uint16(math.MaxUint16), Max[uint16]())
+
+	type Cents int32
+	assert.Equal(t, Cents(math.MaxInt32), Max[Cents]())
+}
+
+func TestAdd(t *testing.T) {
+	tests := map[string]struct {
+		lhs    int8
+		rhs    int8
+		result int8
+		err    error
+	}{
+		"positive":          {lhs: 100, rhs: 27, result: 127},
+		"positive overflow": {lhs: 100, rhs: 28, err: ErrIntOverflow},
+		"negative":          {lhs: -100, rhs: -28, result: -128},
+		"negative overflow": {lhs: -100, rhs: -29, err: ErrIntOverflow},
+		"mixed signs":       {lhs: -128, rhs: 127, result: -1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := Add(test.lhs, test.rhs)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestUnsigned(t *testing.T) {
+	_, err := Sub[uint8](1, 2)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	_, err = Neg[uint32](1)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	result, err := Neg[uint32](0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), result)
+
+	_, err = Mul[uint64](1<<32, 1<<32)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+}
+
+func TestDivision(t *testing.T) {
+	_, err := Div[int](1, 0)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+
+	_, err = Div[int16](math.MinInt16, -1)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	assert.Equal(t, int16(math.MaxInt16), SaturatingDiv[int16](math.MinInt16, -1))
+	assert.Equal(t, int16(math.MinInt16), WrappingDiv[int16](math.MinInt16, -1))
+	assert.Panics(t, func() { SaturatingDiv(1, 0) })
+}
+
+func TestShifts(t *testing.T) {
+	tests := map[string]struct {
+		number int8
+		shift  uint
+		result int8
+		err    error
+	}{
+		"zero":              {number: 0, shift: 100},
+		"fits":              {number: 3, shift: 5, result: 96},
+		"bit shifted out":   {number: 3, shift: 6, err: ErrIntOverflow},
+		"sign changed":      {number: 1, shift: 7, err: ErrIntOverflow},
+		"negative fits":     {number: -1, shift: 7, result: -128},
+		"negative overflow": {number: -2, shift: 7, err: ErrIntOverflow},
+		"too wide shift":    {number: 1, shift: 8, err: ErrIntOverflow},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := Shl(test.number, test.shift)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+
+	assert.Equal(t, int8(-1), Shr[int8](-128, 7))
+	assert.Equal(t, int8(math.MaxInt8), SaturatingShl[int8](3, 6))
+	assert.Equal(t, int8(math.MinInt8), SaturatingShl[int8](-3, 6))
+}
+
+func TestCast(t *testing.T) {
+	result, err := Cast[uint8](255)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(255), result)
+
+	_, err = Cast[uint8](256)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	_, err = Cast[uint64](int8(-1))
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	_, err = Cast[int8](uint8(200))
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	assert.Equal(t, int8(math.MaxInt8), SaturatingCast[int8](uint64(math.MaxUint64)))
+	assert.Equal(t, uint16(0), SaturatingCast[uint16](int64(-5)))
+	assert.Equal(t, uint8(0xFF), WrappingCast[uint8](int16(-1)))
+}
+
+func TestSaturating(t *testing.T) {
+	assert.Equal(t, int32(math.MaxInt32), SaturatingAdd[int32](math.MaxInt32, 1))
+	assert.Equal(t, int32(math.MinInt32), SaturatingSub[int32](math.MinInt32, 1))
+	assert.Equal(t, int32(math.MaxInt32), SaturatingSub[int32](0, math.MinInt32))
+	assert.Equal(t, uint8(0), SaturatingSub[uint8](1, 2))
+	assert.Equal(t, int32(math.MinInt32), SaturatingMul[int32](-65536, 65536))
+	assert.Equal(t, int32(math.MaxInt32), SaturatingMul[int32](-65536, -65536))
+	assert.Equal(t, int32(math.MaxInt32), SaturatingNeg[int32](math.MinInt32))
+	assert.Equal(t, int32(math.MaxInt32), SaturatingAbs[int32](math.MinInt32))
+}
+
+func bigResult[T Integer](value *big.Int) (T, bool) {
+	lower := big.NewInt(0)
+	upper := new(big.Int).SetUint64(uint64(Max[T]()))
+	if signed[T]() {
+		lower.SetInt64(int64(Min[T]()))
+		upper.SetInt64(int64(Max[T]()))
+	}
+
+	if value.Cmp(lower) < 0 || value.Cmp(upper) > 0 {
+		return 0, false
+	}
+
+	if signed[T]() {
+		return T(value.Int64()), true
+	}
+
+	return T(value.Uint64()), true
+}
+
+func toBig[T Integer](value T) *big.Int {
+	if signed[T]() {
+		return big.NewInt(int64(value))
+	}
+
+	return new(big.Int).SetUint64(uint64(value))
+}
+
+func verify[T Integer](t *testing.T, lhs, rhs T) {
+	check := func(name string, expected *big.Int, result T, err error) {
+		value, ok := bigResult[T](expected)
+		if !ok {
+			assert.ErrorIs(t, err, ErrIntOverflow, "%s(%d, %d)", name, lhs, rhs)
+			return
+		}
+
+		assert.NoError(t, err, "%s(%d, %d)", name, lhs, rhs)
+		assert.Equal(t, value, result, "%s(%d, %d)", name, lhs, rhs)
+	}
+
+	x, y := toBig(lhs), toBig(rhs)
+
+	result, err := Add(lhs, rhs)
+	check("Add", new(big.Int).Add(x, y), result, err)
+
+	result, err = Sub(lhs, rhs)
+	check("Sub", new(big.Int).Sub(x, y), result, err)
+
+	result, err = Mul(lhs, rhs)
+	check("Mul", new(big.Int).Mul(x, y), result, err)
+
+	result, err = Neg(lhs)
+	check("Neg", new(big.Int).Neg(x), result, err)
+
+	result, err = Abs(lhs)
+	check("Abs", new(big.Int).Abs(x), result, err)
+
+	shift := uint(rhs) % (bitsOf[T]() + 2)
+	result, err = Shl(lhs, shift)
+	check("Shl", new(big.Int).Lsh(x, shift), result, err)
+
+	if rhs != 0 {
+		result, err = Div(lhs, rhs)
+		check("Div", new(big.Int).Quo(x, y), result, err)
+	}
+
+	var int8Result int8
+	int8Result, err = Cast[int8](lhs)
+	value, ok := bigResult[int8](x)
+	if ok {
+		assert.NoError(t, err)
+		assert.Equal(t, value, int8Result)
+	} else {
+		assert.ErrorIs(t, err, ErrIntOverflow)
+	}
+}
+
+func FuzzInt8(f *testing.F) {
+	f.Add(int8(math.MinInt8), int8(-1))
+	f.Add(int8(100), int8(28))
+	f.Fuzz(func(t *testing.T, lhs, rhs int8) {
+		verify(t, lhs, rhs)
+	})
+}
+
+func FuzzInt64(f *testing.F) {
+	f.Add(int64(math.MinInt64), int64(-1))
+	f.Add(int64(math.MaxInt64), int64(1))
+	f.Add(int64(1<<32), int64(1<<31))
+	f.Fuzz(func(t *testing.T, lhs, rhs int64) {
+		verify(t, lhs, rhs)
+	})
+}
+
+func FuzzUint16(f *testing.F) {
+	f.Add(uint16(0), uint16(1))
+	f.Add(uint16(256), uint16(256))
+	f.Fuzz(func(t *testing.T, lhs, rhs uint16) {
+		verify(t, lhs, rhs)
+	})
+}
+
+func FuzzUint64(f *testing.F) {
+	f.Add(uint64(math.MaxUint64), uint64(1))
+	f.Add(uint64(1<<32), uint64(1<<32))
+	f.Fuzz(func(t *testing.T, lhs, rhs uint64) {
+		verify(t, lhs, rhs)
+	})
+}
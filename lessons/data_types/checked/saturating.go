@@ -0,0 +1,100 @@
+package checked
+
+func SaturatingAdd[T Integer](lhs, rhs T) T {
+	result, err := Add(lhs, rhs)
+	if err == nil {
+		return result
+	}
+
+	if rhs > 0 {
+		return Max[T]()
+	}
+
+	return Min[T]()
+}
+
+func SaturatingSub[T Integer](lhs, rhs T) T {
+	result, err := Sub(lhs, rhs)
+	if err == nil {
+		return result
+	}
+
+	if rhs > 0 {
+		return Min[T]()
+	}
+
+	return Max[T]()
+}
+
+func SaturatingMul[T Integer](lhs, rhs T) T {
+	result, err := Mul(lhs, rhs)
+	if err == nil {
+		return result
+	}
+
+	if (lhs < 0) != (rhs < 0) {
+		return Min[T]()
+	}
+
+	return Max[T]()
+}
+
+// SaturatingDiv panics on division by zero like the built-in operator
+func SaturatingDiv[T Integer](lhs, rhs T) T {
+	result, err := Div(lhs, rhs)
+	if err == ErrDivisionByZero {
+		panic(err)
+	} else if err != nil {
+		return Max[T]()
+	}
+
+	return result
+}
+
+func SaturatingNeg[T Integer](number T) T {
+	result, err := Neg(number)
+	if err == nil {
+		return result
+	}
+
+	if number > 0 {
+		return Min[T]()
+	}
+
+	return Max[T]()
+}
+
+func SaturatingAbs[T Integer](number T) T {
+	result, err := Abs(number)
+	if err != nil {
+		return Max[T]()
+	}
+
+	return result
+}
+
+func SaturatingShl[T Integer](number T, shift uint) T {
+	result, err := Shl(number, shift)
+	if err == nil {
+		return result
+	}
+
+	if number > 0 {
+		return Max[T]()
+	}
+
+	return Min[T]()
+}
+
+func SaturatingCast[To, From Integer](number From) To {
+	result, err := Cast[To](number)
+	if err == nil {
+		return result
+	}
+
+	if number < 0 {
+		return Min[To]()
+	}
+
+	return Max[To]()
+}
@@ -0,0 +1,41 @@
+package checked
+
+// Wrapping operations make the two's complement behaviour of
+// the built-in operators explicit at the call site
+
+func WrappingAdd[T Integer](lhs, rhs T) T {
+	return lhs + rhs
+}
+
+func WrappingSub[T Integer](lhs, rhs T) T {
+	return lhs - rhs
+}
+
+func WrappingMul[T Integer](lhs, rhs T) T {
+	return lhs * rhs
+}
+
+// WrappingDiv panics on division by zero, Min / -1 wraps to Min
+func WrappingDiv[T Integer](lhs, rhs T) T {
+	return lhs / rhs
+}
+
+func WrappingNeg[T Integer](number T) T {
+	return -number
+}
+
+func WrappingAbs[T Integer](number T) T {
+	if number < 0 {
+		return -number
+	}
+
+	return number
+}
+
+func WrappingShl[T Integer](number T, shift uint) T {
+	return number << shift
+}
+
+func WrappingCast[To, From Integer](number From) To {
+	return To(number)
+}
@@ -0,0 +1,452 @@
+package ipaddr
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAddr = errors.New("invalid IP address")
+
+const (
+	familyInvalid = 0
+	family4       = 4
+	family6       = 6
+)
+
+// Addr is an IPv4 or IPv6 address, IPv4 is kept in the low 32 bits of lo.
+// The zero value is not a valid address
+type Addr struct {
+	hi, lo uint64
+	family uint8
+}
+
+func AddrFrom4(octets [4]byte) Addr {
+	return AddrFromUint32(uint32(octets[0])<<24 | uint32(octets[1])<<16 | uint32(octets[2])<<8 | uint32(octets[3]))
+}
+
+func AddrFromUint32(number uint32) Addr {
+	return Addr{lo: uint64(number), family: family4}
+}
+
+func AddrFrom16(octets [16]byte) Addr {
+	a := Addr{family: family6}
+	for i := 0; i < 8; i++ {
+		a.hi = a.hi<<8 | uint64(octets[i])
+		a.lo = a.lo<<8 | uint64(octets[i+8])
+	}
+
+	return a
+}
+
+func (a Addr) IsValid() bool {
+	return a.family != familyInvalid
+}
+
+func (a Addr) Is4() bool {
+	return a.family == family4
+}
+
+func (a Addr) Is6() bool {
+	return a.family == family6
+}
+
+// BitLen is 32 for IPv4, 128 for IPv6 and 0 for the zero Addr
+func (a Addr) BitLen() int {
+	switch a.family {
+	case family4:
+		return 32
+	case family6:
+		return 128
+	default:
+		return 0
+	}
+}
+
+func (a Addr) Uint32() uint32 {
+	return uint32(a.lo)
+}
+
+func (a Addr) As4() [4]byte {
+	number := uint32(a.lo)
+	return [4]byte{byte(number >> 24), byte(number >> 16), byte(number >> 8), byte(number)}
+}
+
+func (a Addr) As16() [16]byte {
+	var octets [16]byte
+	for i := 0; i < 8; i++ {
+		octets[i] = byte(a.hi >> (56 - 8*i))
+		octets[i+8] = byte(a.lo >> (56 - 8*i))
+	}
+
+	return octets
+}
+
+// Compare orders IPv4 addresses before IPv6 ones
+func (a Addr) Compare(other Addr) int {
+	switch {
+	case a.family != other.family:
+		return compare(a.family, other.family)
+	case a.hi != other.hi:
+		return compare(a.hi, other.hi)
+	default:
+		return compare(a.lo, other.lo)
+	}
+}
+
+func compare[T uint8 | uint64](lhs, rhs T) int {
+	if lhs < rhs {
+		return -1
+	} else if lhs > rhs {
+		return 1
+	}
+
+	return 0
+}
+
+// Next returns the following address or the zero Addr on overflow
+func (a Addr) Next() Addr {
+	switch a.family {
+	case family4:
+		if uint32(a.lo) == ^uint32(0) {
+			return Addr{}
+		}
+
+		a.lo++
+	case family6:
+		var carry uint64
+		a.lo, carry = bits.Add64(a.lo, 1, 0)
+		a.hi, carry = bits.Add64(a.hi, 0, carry)
+		if carry != 0 {
+			return Addr{}
+		}
+	}
+
+	return a
+}
+
+// Prev returns the preceding address or the zero Addr on underflow
+func (a Addr) Prev() Addr {
+	switch a.family {
+	case family4:
+		if uint32(a.lo) == 0 {
+			return Addr{}
+		}
+
+		a.lo--
+	case family6:
+		var borrow uint64
+		a.lo, borrow = bits.Sub64(a.lo, 1, 0)
+		a.hi, borrow = bits.Sub64(a.hi, 0, borrow)
+		if borrow != 0 {
+			return Addr{}
+		}
+	}
+
+	return a
+}
+
+// bit returns the bit at index counted from the most significant one
+func (a Addr) bit(index int) int {
+	if a.family == family4 {
+		return int(a.lo>>(31-index)) & 1
+	}
+
+	if index < 64 {
+		return int(a.hi>>(63-index)) & 1
+	}
+
+	return int(a.lo>>(127-index)) & 1
+}
+
+func (a Addr) commonPrefixLen(other Addr) int {
+	if a.family == family4 {
+		return bits.LeadingZeros32(uint32(a.lo ^ other.lo))
+	}
+
+	if a.hi != other.hi {
+		return bits.LeadingZeros64(a.hi ^ other.hi)
+	}
+
+	return 64 + bits.LeadingZeros64(a.lo^other.lo)
+}
+
+// withHostBits clears (or sets) all bits after the first prefixLen bits
+func (a Addr) withHostBits(prefixLen int, set bool) Addr {
+	var hiMask, loMask uint64
+	if a.family == family4 {
+		loMask = uint64(^uint32(0) >> prefixLen)
+	} else if prefixLen <= 64 {
+		hiMask, loMask = ^uint64(0)>>prefixLen, ^uint64(0)
+	} else {
+		loMask = ^uint64(0) >> (prefixLen - 64)
+	}
+
+	if set {
+		a.hi, a.lo = a.hi|hiMask, a.lo|loMask
+	} else {
+		a.hi, a.lo = a.hi&^hiMask, a.lo&^loMask
+	}
+
+	return a
+}
+
+func Parse(address string) (Addr, error) {
+	if strings.Contains(address, ":") {
+		return ParseIPv6(address)
+	}
+
+	return ParseIPv4(address)
+}
+
+func MustParse(address string) Addr {
+	a, err := Parse(address)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+// ParseIPv4 accepts only the dotted decimal form: four decimal
+// octets without signs, spaces or leading zeros
+func ParseIPv4(address string) (Addr, error) {
+	number, ok := parseIPv4(address)
+	if !ok {
+		return Addr{}, invalid(address)
+	}
+
+	return AddrFromUint32(number), nil
+}
+
+func parseIPv4(address string) (uint32, bool) {
+	var result uint32
+	octets := 0
+	for len(address) > 0 {
+		if octets > 0 {
+			if address[0] != '.' {
+				return 0, false
+			}
+			address = address[1:]
+		}
+
+		digits := 0
+		octet := 0
+		for digits < len(address) && address[digits] >= '0' && address[digits] <= '9' {
+			if digits > 0 && octet == 0 {
+				return 0, false // leading zero
+			}
+
+			octet = octet*10 + int(address[digits]-'0')
+			if octet > 255 {
+				return 0, false
+			}
+
+			digits++
+		}
+
+		if digits == 0 {
+			return 0, false
+		}
+
+		address = address[digits:]
+		result = result<<8 | uint32(octet)
+		octets++
+		if octets > 4 {
+			return 0, false
+		}
+	}
+
+	return result, octets == 4
+}
+
+// ParseIPv6 accepts the forms from RFC 4291 including "::" and a trailing
+// dotted IPv4 part, zones are not supported
+func ParseIPv6(address string) (Addr, error) {
+	var groups [8]uint16
+	count := 0
+	ellipsis := -1
+
+	rest := address
+	if strings.HasPrefix(rest, "::") {
+		ellipsis = 0
+		rest = rest[2:]
+	}
+
+	for len(rest) > 0 {
+		end := 0
+		for end < len(rest) && end < 5 && isHex(rest[end]) {
+			end++
+		}
+
+		// embedded IPv4 in the last 32 bits
+		if end < len(rest) && rest[end] == '.' {
+			if count > 6 {
+				return Addr{}, invalid(address)
+			}
+
+			number, ok := parseIPv4(rest)
+			if !ok {
+				return Addr{}, invalid(address)
+			}
+
+			groups[count] = uint16(number >> 16)
+			groups[count+1] = uint16(number)
+			count += 2
+			rest = ""
+			break
+		}
+
+		if end == 0 || end > 4 || count == 8 {
+			return Addr{}, invalid(address)
+		}
+
+		group, _ := strconv.ParseUint(rest[:end], 16, 16)
+		groups[count] = uint16(group)
+		count++
+		rest = rest[end:]
+
+		if len(rest) == 0 {
+			break
+		}
+
+		if rest[0] != ':' || len(rest) == 1 {
+			return Addr{}, invalid(address)
+		}
+
+		rest = rest[1:]
+		if rest[0] == ':' {
+			if ellipsis >= 0 {
+				return Addr{}, invalid(address)
+			}
+
+			ellipsis = count
+			rest = rest[1:]
+		}
+	}
+
+	if ellipsis >= 0 {
+		if count == 8 {
+			return Addr{}, invalid(address)
+		}
+
+		shift := 8 - count
+		copy(groups[ellipsis+shift:], groups[ellipsis:count])
+		clear(groups[ellipsis : ellipsis+shift])
+	} else if count != 8 {
+		return Addr{}, invalid(address)
+	}
+
+	a := Addr{family: family6}
+	for i := 0; i < 4; i++ {
+		a.hi = a.hi<<16 | uint64(groups[i])
+		a.lo = a.lo<<16 | uint64(groups[i+4])
+	}
+
+	return a, nil
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func invalid(address string) error {
+	return fmt.Errorf("%w: %q", ErrInvalidAddr, address)
+}
+
+// String uses the canonical text form from RFC 5952 for IPv6
+func (a Addr) String() string {
+	switch a.family {
+	case family4:
+		return formatIPv4(uint32(a.lo))
+	case family6:
+		return a.formatIPv6()
+	default:
+		return "invalid IP"
+	}
+}
+
+func formatIPv4(number uint32) string {
+	buffer := make([]byte, 0, len("255.255.255.255"))
+	for i := 3; i >= 0; i-- {
+		buffer = strconv.AppendUint(buffer, uint64(byte(number>>(8*i))), 10)
+		if i > 0 {
+			buffer = append(buffer, '.')
+		}
+	}
+
+	return string(buffer)
+}
+
+func (a Addr) formatIPv6() string {
+	var groups [8]uint16
+	for i := 0; i < 4; i++ {
+		groups[i] = uint16(a.hi >> (48 - 16*i))
+		groups[i+4] = uint16(a.lo >> (48 - 16*i))
+	}
+
+	// IPv4-mapped addresses keep the dotted form
+	if a.hi == 0 && a.lo>>32 == 0xFFFF {
+		return "::ffff:" + formatIPv4(uint32(a.lo))
+	}
+
+	// the longest run of at least two zero groups, the first one on ties
+	bestStart, bestLength := -1, 1
+	for i := 0; i < 8; {
+		if groups[i] != 0 {
+			i++
+			continue
+		}
+
+		start := i
+		for i < 8 && groups[i] == 0 {
+			i++
+		}
+
+		if i-start > bestLength {
+			bestStart, bestLength = start, i-start
+		}
+	}
+
+	buffer := make([]byte, 0, len("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+	for i := 0; i < 8; i++ {
+		if i == bestStart {
+			buffer = append(buffer, ':', ':')
+			i += bestLength - 1
+			continue
+		}
+
+		if i > 0 && i != bestStart+bestLength {
+			buffer = append(buffer, ':')
+		}
+
+		buffer = strconv.AppendUint(buffer, uint64(groups[i]), 16)
+	}
+
+	return string(buffer)
+}
+
+func (a Addr) MarshalText() ([]byte, error) {
+	if !a.IsValid() {
+		return []byte(""), nil
+	}
+
+	return []byte(a.String()), nil
+}
+
+func (a *Addr) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = Addr{}
+		return nil
+	}
+
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
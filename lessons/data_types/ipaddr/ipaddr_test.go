@@ -0,0 +1,287 @@
+package ipaddr
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -bench=. .
+
+func TestParseIPv4(t *testing.T) {
+	tests := map[string]struct {
+		address string
+		result  uint32
+		err     bool
+	}{
+		"zero":          {address: "0.0.0.0", result: 0},
+		"broadcast":     {address: "255.255.255.255", result: 0xFFFFFFFF},
+		"lesson sample": {address: "255.255.6.0", result: 0xFFFF0600},
+		"leading zero":  {address: "10.01.0.1", err: true},
+		"plus sign":     {address: "+1.2.3.4", err: true},
+		"inner plus":    {address: "1.+2.3.4", err: true},
+		"too big":       {address: "1.2.3.256", err: true},
+		"too short":     {address: "1.2.3", err: true},
+		"too long":      {address: "1.2.3.4.5", err: true},
+		"empty octet":   {address: "1..3.4", err: true},
+		"trailing dot":  {address: "1.2.3.4.", err: true},
+		"spaces":        {address: " 1.2.3.4", err: true},
+		"empty":         {address: "", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr, err := ParseIPv4(test.address)
+			if test.err {
+				assert.ErrorIs(t, err, ErrInvalidAddr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.result, addr.Uint32())
+			assert.Equal(t, test.address, addr.String())
+		})
+	}
+}
+
+func TestParseIPv6(t *testing.T) {
+	tests := map[string]struct {
+		address   string
+		canonical string
+		err       bool
+	}{
+		"loopback":          {address: "::1", canonical: "::1"},
+		"unspecified":       {address: "::", canonical: "::"},
+		"full form":         {address: "2001:0db8:0000:0000:0000:ff00:0042:8329", canonical: "2001:db8::ff00:42:8329"},
+		"upper case":        {address: "2001:DB8::1", canonical: "2001:db8::1"},
+		"trailing ellipsis": {address: "fe80::", canonical: "fe80::"},
+		"longest run":       {address: "1:0:0:2:0:0:0:3", canonical: "1:0:0:2::3"},
+		"first run on tie":  {address: "1:0:0:2:3:0:0:4", canonical: "1::2:3:0:0:4"},
+		"single zero group": {address: "1:2:3:4:5:6:0:8", canonical: "1:2:3:4:5:6:0:8"},
+		"ipv4 mapped":       {address: "::ffff:192.0.2.1", canonical: "::ffff:192.0.2.1"},
+		"embedded ipv4":     {address: "64:ff9b::192.0.2.33", canonical: "64:ff9b::c000:221"},
+		"two ellipses":      {address: "1::2::3", err: true},
+		"too many groups":   {address: "1:2:3:4:5:6:7:8:9", err: true},
+		"too few groups":    {address: "1:2:3:4:5:6:7", err: true},
+		"full with ellips":  {address: "1:2:3:4::5:6:7:8", err: true},
+		"long group":        {address: "12345::", err: true},
+		"leading colon":     {address: ":1::", err: true},
+		"trailing colon":    {address: "1::2:", err: true},
+		"triple colon":      {address: ":::", err: true},
+		"bad ipv4":          {address: "::1.2.3.04", err: true},
+		"ipv4 not last":     {address: "::1.2.3.4:5", err: true},
+		"zone":              {address: "fe80::1%eth0", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr, err := ParseIPv6(test.address)
+			if test.err {
+				assert.ErrorIs(t, err, ErrInvalidAddr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.canonical, addr.String())
+
+			standard := netip.MustParseAddr(test.address)
+			assert.Equal(t, standard.As16(), addr.As16())
+		})
+	}
+}
+
+func TestAddrOrder(t *testing.T) {
+	assert.Equal(t, "0.0.1.0", MustParse("0.0.0.255").Next().String())
+	assert.False(t, MustParse("255.255.255.255").Next().IsValid())
+	assert.False(t, MustParse("0.0.0.0").Prev().IsValid())
+	assert.Equal(t, "::2:0:0:0", MustParse("::1:ffff:ffff:ffff").Next().String())
+	assert.Equal(t, "::1:ffff:ffff:ffff", MustParse("::2:0:0:0").Prev().String())
+	assert.False(t, MustParse("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff").Next().IsValid())
+
+	assert.Equal(t, -1, MustParse("1.2.3.4").Compare(MustParse("::")))
+	assert.Equal(t, 1, MustParse("1.2.3.5").Compare(MustParse("1.2.3.4")))
+	assert.Equal(t, 0, MustParse("::1").Compare(MustParse("0::1")))
+}
+
+func TestPrefix(t *testing.T) {
+	tests := map[string]struct {
+		prefix    string
+		network   string
+		broadcast string
+	}{
+		"ipv4":       {prefix: "192.168.1.77/26", network: "192.168.1.64", broadcast: "192.168.1.127"},
+		"ipv4 host":  {prefix: "10.1.2.3/32", network: "10.1.2.3", broadcast: "10.1.2.3"},
+		"ipv4 all":   {prefix: "10.1.2.3/0", network: "0.0.0.0", broadcast: "255.255.255.255"},
+		"ipv6":       {prefix: "2001:db8:abcd:12::1/48", network: "2001:db8:abcd::", broadcast: "2001:db8:abcd:ffff:ffff:ffff:ffff:ffff"},
+		"ipv6 in lo": {prefix: "2001:db8::1234/120", network: "2001:db8::1200", broadcast: "2001:db8::12ff"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prefix, err := ParsePrefix(test.prefix)
+			require.NoError(t, err)
+			assert.Equal(t, test.network, prefix.Network().String())
+			assert.Equal(t, test.broadcast, prefix.Broadcast().String())
+			assert.True(t, prefix.Contains(prefix.Network()))
+			assert.True(t, prefix.Contains(prefix.Broadcast()))
+			assert.Equal(t, test.prefix, prefix.String())
+		})
+	}
+
+	prefix := MustParsePrefix("10.0.0.0/8")
+	assert.True(t, prefix.Contains(MustParse("10.255.0.1")))
+	assert.False(t, prefix.Contains(MustParse("11.0.0.0")))
+	assert.False(t, prefix.Contains(MustParse("::a00:1")))
+	assert.True(t, prefix.Overlaps(MustParsePrefix("10.1.0.0/16")))
+	assert.False(t, prefix.Overlaps(MustParsePrefix("11.0.0.0/16")))
+
+	for _, invalid := range []string{"10.0.0.0", "10.0.0.0/33", "10.0.0.0/08", "10.0.0.0/", "::/129", "::/-1", "10.0.0.0/a"} {
+		_, err := ParsePrefix(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRange(t *testing.T) {
+	var addresses []string
+	for addr := range MustParsePrefix("10.0.0.254/31").All() {
+		addresses = append(addresses, addr.String())
+	}
+
+	assert.Equal(t, []string{"10.0.0.254", "10.0.0.255"}, addresses)
+
+	count := 0
+	for range Range(MustParse("255.255.255.250"), MustParse("255.255.255.255")) {
+		count++
+	}
+
+	assert.Equal(t, 6, count)
+
+	count = 0
+	for range MustParsePrefix("::/0").All() {
+		if count++; count == 1000 {
+			break
+		}
+	}
+
+	assert.Equal(t, 1000, count)
+}
+
+func TestTable(t *testing.T) {
+	table := Table[string]{}
+	table.Insert(MustParsePrefix("0.0.0.0/0"), "default")
+	table.Insert(MustParsePrefix("10.0.0.0/8"), "private")
+	table.Insert(MustParsePrefix("10.1.0.0/16"), "office")
+	table.Insert(MustParsePrefix("10.1.2.0/24"), "lab")
+	table.Insert(MustParsePrefix("10.2.0.0/16"), "dc")
+	table.Insert(MustParsePrefix("2001:db8::/32"), "docs")
+	assert.Equal(t, 6, table.Len())
+
+	tests := map[string]struct {
+		address string
+		prefix  string
+		value   string
+		found   bool
+	}{
+		"most specific": {address: "10.1.2.3", prefix: "10.1.2.0/24", value: "lab", found: true},
+		"middle":        {address: "10.1.3.3", prefix: "10.1.0.0/16", value: "office", found: true},
+		"sibling":       {address: "10.2.3.3", prefix: "10.2.0.0/16", value: "dc", found: true},
+		"private":       {address: "10.3.0.1", prefix: "10.0.0.0/8", value: "private", found: true},
+		"default":       {address: "8.8.8.8", prefix: "0.0.0.0/0", value: "default", found: true},
+		"ipv6":          {address: "2001:db8::1", prefix: "2001:db8::/32", value: "docs", found: true},
+		"ipv6 missing":  {address: "2001:db9::1", found: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prefix, value, found := table.Lookup(MustParse(test.address))
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.value, value)
+			if found {
+				assert.Equal(t, test.prefix, prefix.String())
+			}
+		})
+	}
+
+	value, found := table.Get(MustParsePrefix("10.1.77.1/16"))
+	assert.True(t, found)
+	assert.Equal(t, "office", value)
+
+	assert.True(t, table.Delete(MustParsePrefix("10.1.0.0/16")))
+	assert.False(t, table.Delete(MustParsePrefix("10.1.0.0/16")))
+	_, value, _ = table.Lookup(MustParse("10.1.3.3"))
+	assert.Equal(t, "private", value)
+	_, value, _ = table.Lookup(MustParse("10.1.2.3"))
+	assert.Equal(t, "lab", value)
+	assert.Equal(t, 5, table.Len())
+
+	var prefixes []string
+	for prefix := range table.All() {
+		prefixes = append(prefixes, prefix.String())
+	}
+
+	assert.Equal(t, []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.2.0/24", "10.2.0.0/16", "2001:db8::/32"}, prefixes)
+}
+
+func randomPrefix(random *rand.Rand) Prefix {
+	prefix, _ := PrefixFrom(AddrFromUint32(random.Uint32()&0xFF0F0000), random.Intn(25))
+	return prefix.Masked()
+}
+
+func TestTableAgainstLinearScan(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	table := Table[int]{}
+	prefixes := make(map[Prefix]int)
+	for i := 0; i < 2000; i++ {
+		prefix := randomPrefix(random)
+		table.Insert(prefix, i)
+		prefixes[prefix] = i
+	}
+
+	for i := 0; i < 500; i++ {
+		prefix := randomPrefix(random)
+		_, found := prefixes[prefix]
+		assert.Equal(t, found, table.Delete(prefix))
+		delete(prefixes, prefix)
+	}
+
+	assert.Equal(t, len(prefixes), table.Len())
+
+	for i := 0; i < 10000; i++ {
+		addr := AddrFromUint32(random.Uint32() & 0xFF0FFFFF)
+
+		best, bestValue := Prefix{}, 0
+		for prefix, value := range prefixes {
+			if prefix.Contains(addr) && (!best.IsValid() || prefix.Bits() > best.Bits()) {
+				best, bestValue = prefix, value
+			}
+		}
+
+		prefix, value, found := table.Lookup(addr)
+		require.Equal(t, best.IsValid(), found, addr.String())
+		if found {
+			require.Equal(t, best, prefix, addr.String())
+			require.Equal(t, bestValue, value, addr.String())
+		}
+	}
+}
+
+var Result bool
+
+func BenchmarkLookup(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	table := Table[int]{}
+	for i := 0; i < 100000; i++ {
+		prefix, _ := PrefixFrom(AddrFromUint32(random.Uint32()), 8+random.Intn(17))
+		table.Insert(prefix, i)
+	}
+
+	addr := MustParse("93.184.216.34")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, Result = table.Lookup(addr)
+	}
+}
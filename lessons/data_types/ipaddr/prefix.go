@@ -0,0 +1,128 @@
+package ipaddr
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+var ErrInvalidPrefix = errors.New("invalid CIDR prefix")
+
+// Prefix is an address with a prefix length as in CIDR notation 10.0.0.0/8
+type Prefix struct {
+	addr Addr
+	bits int
+}
+
+func PrefixFrom(addr Addr, bits int) (Prefix, error) {
+	if !addr.IsValid() || bits < 0 || bits > addr.BitLen() {
+		return Prefix{}, fmt.Errorf("%w: %s/%d", ErrInvalidPrefix, addr, bits)
+	}
+
+	return Prefix{addr: addr, bits: bits}, nil
+}
+
+// ParsePrefix keeps host bits, use Masked to clear them
+func ParsePrefix(prefix string) (Prefix, error) {
+	address, length, found := strings.Cut(prefix, "/")
+	if !found {
+		return Prefix{}, fmt.Errorf("%w: %q has no prefix length", ErrInvalidPrefix, prefix)
+	}
+
+	addr, err := Parse(address)
+	if err != nil {
+		return Prefix{}, err
+	}
+
+	bits := 0
+	for i, c := range []byte(length) {
+		if c < '0' || c > '9' || (i > 0 && bits == 0) || bits > addr.BitLen() {
+			return Prefix{}, fmt.Errorf("%w: %q", ErrInvalidPrefix, prefix)
+		}
+
+		bits = bits*10 + int(c-'0')
+	}
+
+	if len(length) == 0 || bits > addr.BitLen() {
+		return Prefix{}, fmt.Errorf("%w: %q", ErrInvalidPrefix, prefix)
+	}
+
+	return Prefix{addr: addr, bits: bits}, nil
+}
+
+func MustParsePrefix(prefix string) Prefix {
+	p, err := ParsePrefix(prefix)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func (p Prefix) IsValid() bool {
+	return p.addr.IsValid()
+}
+
+func (p Prefix) Addr() Addr {
+	return p.addr
+}
+
+func (p Prefix) Bits() int {
+	return p.bits
+}
+
+func (p Prefix) Masked() Prefix {
+	return Prefix{addr: p.Network(), bits: p.bits}
+}
+
+// Network returns the first address of the prefix
+func (p Prefix) Network() Addr {
+	return p.addr.withHostBits(p.bits, false)
+}
+
+// Broadcast returns the last address of the prefix, IPv6 has no broadcast
+// but the last address is still useful for ranges
+func (p Prefix) Broadcast() Addr {
+	return p.addr.withHostBits(p.bits, true)
+}
+
+func (p Prefix) Contains(addr Addr) bool {
+	return p.IsValid() && addr.family == p.addr.family && p.addr.commonPrefixLen(addr) >= p.bits
+}
+
+func (p Prefix) Overlaps(other Prefix) bool {
+	if !p.IsValid() || p.addr.family != other.addr.family {
+		return false
+	}
+
+	return p.addr.commonPrefixLen(other.addr) >= min(p.bits, other.bits)
+}
+
+// All iterates over every address of the prefix in ascending order
+func (p Prefix) All() iter.Seq[Addr] {
+	return Range(p.Network(), p.Broadcast())
+}
+
+// Range iterates from first to last inclusive, both must be of the same family
+func Range(first, last Addr) iter.Seq[Addr] {
+	return func(yield func(Addr) bool) {
+		if !first.IsValid() || first.family != last.family {
+			return
+		}
+
+		for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+func (p Prefix) String() string {
+	if !p.IsValid() {
+		return "invalid Prefix"
+	}
+
+	return fmt.Sprintf("%s/%d", p.addr, p.bits)
+}
@@ -0,0 +1,183 @@
+package ipaddr
+
+import "iter"
+
+type node[V any] struct {
+	prefix   Prefix // masked
+	value    V
+	hasValue bool
+	children [2]*node[V]
+}
+
+// Table is a path-compressed binary (Patricia) trie keyed by prefixes,
+// it finds the most specific prefix containing an address
+type Table[V any] struct {
+	root4 *node[V]
+	root6 *node[V]
+	size  int
+}
+
+func (t *Table[V]) root(addr Addr) **node[V] {
+	if addr.Is4() {
+		return &t.root4
+	}
+
+	return &t.root6
+}
+
+func (t *Table[V]) Len() int {
+	return t.size
+}
+
+// Insert adds or replaces the value for the prefix, host bits are ignored
+func (t *Table[V]) Insert(prefix Prefix, value V) {
+	if !prefix.IsValid() {
+		return
+	}
+
+	prefix = prefix.Masked()
+	current := t.root(prefix.addr)
+	for {
+		n := *current
+		if n == nil {
+			*current = &node[V]{prefix: prefix, value: value, hasValue: true}
+			t.size++
+			return
+		}
+
+		common := min(n.prefix.addr.commonPrefixLen(prefix.addr), n.prefix.bits, prefix.bits)
+		switch {
+		case common == n.prefix.bits && common == prefix.bits:
+			if !n.hasValue {
+				t.size++
+			}
+
+			n.value, n.hasValue = value, true
+			return
+		case common == n.prefix.bits:
+			current = &n.children[prefix.addr.bit(n.prefix.bits)]
+		case common == prefix.bits:
+			inserted := &node[V]{prefix: prefix, value: value, hasValue: true}
+			inserted.children[n.prefix.addr.bit(prefix.bits)] = n
+			*current = inserted
+			t.size++
+			return
+		default:
+			glue := &node[V]{prefix: Prefix{addr: prefix.addr.withHostBits(common, false), bits: common}}
+			inserted := &node[V]{prefix: prefix, value: value, hasValue: true}
+			glue.children[prefix.addr.bit(common)] = inserted
+			glue.children[n.prefix.addr.bit(common)] = n
+			*current = glue
+			t.size++
+			return
+		}
+	}
+}
+
+// Get returns the value stored for exactly this prefix
+func (t *Table[V]) Get(prefix Prefix) (V, bool) {
+	var zero V
+	if !prefix.IsValid() {
+		return zero, false
+	}
+
+	prefix = prefix.Masked()
+	n := *t.root(prefix.addr)
+	for n != nil && n.prefix.bits <= prefix.bits && n.prefix.Contains(prefix.addr) {
+		if n.prefix.bits == prefix.bits {
+			return n.value, n.hasValue
+		}
+
+		n = n.children[prefix.addr.bit(n.prefix.bits)]
+	}
+
+	return zero, false
+}
+
+// Lookup performs the longest prefix match
+func (t *Table[V]) Lookup(addr Addr) (Prefix, V, bool) {
+	var match *node[V]
+	if addr.IsValid() {
+		n := *t.root(addr)
+		for n != nil && n.prefix.Contains(addr) {
+			if n.hasValue {
+				match = n
+			}
+
+			if n.prefix.bits == addr.BitLen() {
+				break
+			}
+
+			n = n.children[addr.bit(n.prefix.bits)]
+		}
+	}
+
+	if match == nil {
+		var zero V
+		return Prefix{}, zero, false
+	}
+
+	return match.prefix, match.value, true
+}
+
+func (t *Table[V]) Delete(prefix Prefix) bool {
+	if !prefix.IsValid() {
+		return false
+	}
+
+	prefix = prefix.Masked()
+	deleted := false
+	root := t.root(prefix.addr)
+	*root = t.delete(*root, prefix, &deleted)
+	return deleted
+}
+
+func (t *Table[V]) delete(n *node[V], prefix Prefix, deleted *bool) *node[V] {
+	if n == nil || n.prefix.bits > prefix.bits || !n.prefix.Contains(prefix.addr) {
+		return n
+	}
+
+	if n.prefix.bits < prefix.bits {
+		bit := prefix.addr.bit(n.prefix.bits)
+		n.children[bit] = t.delete(n.children[bit], prefix, deleted)
+	} else if n.hasValue {
+		var zero V
+		n.value, n.hasValue = zero, false
+		t.size--
+		*deleted = true
+	}
+
+	// nodes without a value only exist to join two subtrees
+	if n.hasValue {
+		return n
+	}
+
+	switch {
+	case n.children[0] == nil:
+		return n.children[1]
+	case n.children[1] == nil:
+		return n.children[0]
+	default:
+		return n
+	}
+}
+
+// All iterates over stored prefixes, IPv4 first, in address order
+// with shorter prefixes before the longer ones they contain
+func (t *Table[V]) All() iter.Seq2[Prefix, V] {
+	return func(yield func(Prefix, V) bool) {
+		_ = walk(t.root4, yield) && walk(t.root6, yield)
+	}
+}
+
+func walk[V any](n *node[V], yield func(Prefix, V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.hasValue && !yield(n.prefix, n.value) {
+		return false
+	}
+
+	return walk(n.children[0], yield) && walk(n.children[1], yield)
+}
@@ -0,0 +1,261 @@
+package iterators
+
+import (
+	"context"
+	"iter"
+)
+
+func Map[T, U any](seq iter.Seq[T], action func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for value := range seq {
+			if !yield(action(value)) {
+				return
+			}
+		}
+	}
+}
+
+func Filter[T any](seq iter.Seq[T], action func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := range seq {
+			if action(value) && !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+func FlatMap[T, U any](seq iter.Seq[T], action func(T) iter.Seq[U]) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for value := range seq {
+			for inner := range action(value) {
+				if !yield(inner) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func Take[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if count <= 0 {
+			return
+		}
+
+		taken := 0
+		for value := range seq {
+			if !yield(value) {
+				return
+			}
+
+			taken++
+			if taken == count {
+				return
+			}
+		}
+	}
+}
+
+func Skip[T any](seq iter.Seq[T], count int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		skipped := 0
+		for value := range seq {
+			if skipped < count {
+				skipped++
+				continue
+			}
+
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk yields consecutive non-overlapping slices, the last one may be shorter
+func Chunk[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size <= 0 {
+		panic("iterators: chunk size must be positive")
+	}
+
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, size)
+		for value := range seq {
+			chunk = append(chunk, value)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+
+				chunk = make([]T, 0, size)
+			}
+		}
+
+		if len(chunk) != 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Window yields sliding windows of the given size, the yielded
+// slice is reused between iterations and must be copied to be retained
+func Window[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size <= 0 {
+		panic("iterators: window size must be positive")
+	}
+
+	return func(yield func([]T) bool) {
+		window := make([]T, 0, size)
+		for value := range seq {
+			if len(window) == size {
+				copy(window, window[1:])
+				window[size-1] = value
+			} else {
+				window = append(window, value)
+			}
+
+			if len(window) == size && !yield(window) {
+				return
+			}
+		}
+	}
+}
+
+// Zip stops as soon as one of the sequences is exhausted
+func Zip[T, U any](lhs iter.Seq[T], rhs iter.Seq[U]) iter.Seq2[T, U] {
+	return func(yield func(T, U) bool) {
+		next, stop := iter.Pull(rhs)
+		defer stop()
+
+		for left := range lhs {
+			right, ok := next()
+			if !ok || !yield(left, right) {
+				return
+			}
+		}
+	}
+}
+
+func Distinct[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[T]struct{})
+		for value := range seq {
+			if _, found := seen[value]; found {
+				continue
+			}
+
+			seen[value] = struct{}{}
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// GroupBy is a terminal operation, groups keep the order of the sequence
+func GroupBy[T any, K comparable](seq iter.Seq[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for value := range seq {
+		k := key(value)
+		groups[k] = append(groups[k], value)
+	}
+
+	return groups
+}
+
+func Reduce[T, U any](seq iter.Seq[T], initial U, action func(U, T) U) U {
+	result := initial
+	for value := range seq {
+		result = action(result, value)
+	}
+
+	return result
+}
+
+func FromSlice[T any](data []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, value := range data {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+func ToSlice[T any](seq iter.Seq[T]) []T {
+	var result []T
+	for value := range seq {
+		result = append(result, value)
+	}
+
+	return result
+}
+
+func FromMap[K comparable, V any](data map[K]V) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, value := range data {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+func ToMap[K comparable, V any](seq iter.Seq2[K, V]) map[K]V {
+	result := make(map[K]V)
+	for key, value := range seq {
+		result[key] = value
+	}
+
+	return result
+}
+
+func Keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range seq {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+func Values[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range seq {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// FromChannel yields values until the channel is closed
+func FromChannel[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := range ch {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// ToChannel sends values from a separate goroutine, the channel is closed
+// when the sequence is exhausted or the context is cancelled
+func ToChannel[T any](ctx context.Context, seq iter.Seq[T]) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for value := range seq {
+			select {
+			case ch <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,184 @@
+package iterators
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v iterators_test.go iterators.go
+
+func naturals() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 1; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	tests := map[string]struct {
+		data   []int
+		result []int
+	}{
+		"nil numbers": {},
+		"empty numbers": {
+			data: []int{},
+		},
+		"numbers": {
+			data:   []int{1, 2, 3, 4, 5, 6, 7, 8},
+			result: []int{4, 16, 36},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			seq := FromSlice(test.data)
+			seq = Filter(seq, func(number int) bool { return number%2 == 0 })
+			seq = Map(seq, func(number int) int { return number * number })
+			result := ToSlice(Take(seq, 3))
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestInfiniteSequence(t *testing.T) {
+	var calls int
+	seq := Map(naturals(), func(number int) int {
+		calls++
+		return number * 10
+	})
+
+	result := ToSlice(Take(Skip(seq, 2), 3))
+	assert.Equal(t, []int{30, 40, 50}, result)
+	assert.Equal(t, 5, calls)
+}
+
+func TestFlatMap(t *testing.T) {
+	seq := FlatMap(FromSlice([]int{1, 2, 3}), func(number int) iter.Seq[int] {
+		return FromSlice(slices.Repeat([]int{number}, number))
+	})
+
+	assert.Equal(t, []int{1, 2, 2, 3, 3, 3}, ToSlice(seq))
+	assert.Equal(t, []int{1, 2}, ToSlice(Take(seq, 2)))
+}
+
+func TestChunkAndWindow(t *testing.T) {
+	data := []int{1, 2, 3, 4, 5}
+
+	chunks := ToSlice(Chunk(FromSlice(data), 2))
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+
+	var windows [][]int
+	for window := range Window(FromSlice(data), 3) {
+		windows = append(windows, slices.Clone(window))
+	}
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, windows)
+	assert.Nil(t, ToSlice(Window(FromSlice(data), 6)))
+}
+
+func TestZip(t *testing.T) {
+	numbers := FromSlice([]int{1, 2, 3})
+	names := Map(naturals(), strconv.Itoa)
+
+	result := make(map[int]string)
+	for number, name := range Zip(numbers, names) {
+		result[number] = name
+	}
+
+	assert.Equal(t, map[int]string{1: "1", 2: "2", 3: "3"}, result)
+}
+
+func TestDistinctAndGroupBy(t *testing.T) {
+	data := FromSlice([]int{3, 1, 3, 2, 1, 4})
+	assert.Equal(t, []int{3, 1, 2, 4}, ToSlice(Distinct(data)))
+
+	groups := GroupBy(data, func(number int) bool { return number%2 == 0 })
+	assert.Equal(t, map[bool][]int{false: {3, 1, 3, 1}, true: {2, 4}}, groups)
+}
+
+func TestReduce(t *testing.T) {
+	tests := map[string]struct {
+		initial int
+		data    []int
+		result  int
+	}{
+		"nil numbers": {},
+		"sum of numbers": {
+			data:   []int{1, 2, 3, 4, 5},
+			result: 15,
+		},
+		"sum of numbers with initial value": {
+			initial: 10,
+			data:    []int{1, 2, 3, 4, 5},
+			result:  25,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := Reduce(FromSlice(test.data), test.initial, func(lhs, rhs int) int {
+				return lhs + rhs
+			})
+
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	data := map[string]int{"a": 1, "b": 2}
+	assert.Equal(t, data, ToMap(FromMap(data)))
+
+	keys := ToSlice(Keys(FromMap(data)))
+	slices.Sort(keys)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := ToChannel(ctx, Take(naturals(), 4))
+	assert.Equal(t, []int{1, 2, 3, 4}, ToSlice(FromChannel(ch)))
+}
+
+var Result int
+
+func BenchmarkSlices(b *testing.B) {
+	data := make([]int, 1024)
+	for i := 0; i < b.N; i++ {
+		var mapped []int
+		for _, number := range data {
+			mapped = append(mapped, number+1)
+		}
+
+		var filtered []int
+		for _, number := range mapped {
+			if number%2 == 0 {
+				filtered = append(filtered, number)
+			}
+		}
+
+		sum := 0
+		for _, number := range filtered {
+			sum += number
+		}
+
+		Result = sum
+	}
+}
+
+func BenchmarkIterators(b *testing.B) {
+	data := make([]int, 1024)
+	for i := 0; i < b.N; i++ {
+		seq := Map(FromSlice(data), func(number int) int { return number + 1 })
+		seq = Filter(seq, func(number int) bool { return number%2 == 0 })
+		Result = Reduce(seq, 0, func(lhs, rhs int) int { return lhs + rhs })
+	}
+}
@@ -0,0 +1,204 @@
+package memoize
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Shared    uint64 // calls that waited for an identical call in flight
+	Evictions uint64
+	Size      int
+}
+
+type options struct {
+	ttl         time.Duration
+	maxSize     int
+	cacheErrors bool
+	errorTTL    time.Duration
+	now         func() time.Time
+}
+
+type Option func(*options)
+
+// WithTTL sets how long a successful result stays valid, zero means forever
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithMaxSize bounds the number of cached results, the least
+// recently used result is evicted first
+func WithMaxSize(size int) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// WithErrorCaching enables negative caching of errors for the given ttl,
+// errors are not cached for a non-positive ttl
+func WithErrorCaching(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheErrors = ttl > 0
+		o.errorTTL = ttl
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	err       error
+	expiresAt time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	panic any
+}
+
+type Memoized[K comparable, V any] struct {
+	fn      func(K) (V, error)
+	options options
+
+	mutex    sync.Mutex
+	entries  map[K]*list.Element
+	order    *list.List // front is the most recently used
+	inflight map[K]*call[V]
+	stats    Stats
+}
+
+func Memoize[K comparable, V any](fn func(K) (V, error), opts ...Option) *Memoized[K, V] {
+	o := options{now: time.Now}
+	for _, option := range opts {
+		option(&o)
+	}
+
+	return &Memoized[K, V]{
+		fn:       fn,
+		options:  o,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+		inflight: make(map[K]*call[V]),
+	}
+}
+
+func (m *Memoized[K, V]) Get(key K) (V, error) {
+	m.mutex.Lock()
+	if element, found := m.entries[key]; found {
+		e := element.Value.(*entry[K, V])
+		if e.expiresAt.IsZero() || m.options.now().Before(e.expiresAt) {
+			m.order.MoveToFront(element)
+			m.stats.Hits++
+			m.mutex.Unlock()
+			return e.value, e.err
+		}
+
+		m.remove(element)
+	}
+
+	if c, found := m.inflight[key]; found {
+		m.stats.Shared++
+		m.mutex.Unlock()
+		<-c.done
+		return c.result()
+	}
+
+	c := &call[V]{done: make(chan struct{})}
+	m.inflight[key] = c
+	m.stats.Misses++
+	m.mutex.Unlock()
+
+	m.do(key, c)
+	return c.result()
+}
+
+func (m *Memoized[K, V]) do(key K, c *call[V]) {
+	defer func() {
+		if value := recover(); value != nil {
+			c.panic = value
+		}
+
+		m.mutex.Lock()
+		delete(m.inflight, key)
+		if c.panic == nil {
+			m.store(key, c.value, c.err)
+		}
+		m.mutex.Unlock()
+
+		close(c.done)
+	}()
+
+	c.value, c.err = m.fn(key)
+}
+
+func (c *call[V]) result() (V, error) {
+	// the original value is kept, so callers can recover errors from it
+	if c.panic != nil {
+		panic(c.panic)
+	}
+
+	return c.value, c.err
+}
+
+func (m *Memoized[K, V]) store(key K, value V, err error) {
+	ttl := m.options.ttl
+	if err != nil {
+		if !m.options.cacheErrors {
+			return
+		}
+
+		ttl = m.options.errorTTL
+	}
+
+	e := &entry[K, V]{key: key, value: value, err: err}
+	if ttl > 0 {
+		e.expiresAt = m.options.now().Add(ttl)
+	}
+
+	if element, found := m.entries[key]; found {
+		element.Value = e
+		m.order.MoveToFront(element)
+	} else {
+		m.entries[key] = m.order.PushFront(e)
+	}
+
+	for m.options.maxSize > 0 && m.order.Len() > m.options.maxSize {
+		m.remove(m.order.Back())
+		m.stats.Evictions++
+	}
+}
+
+func (m *Memoized[K, V]) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*entry[K, V]).key)
+}
+
+func (m *Memoized[K, V]) Forget(key K) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, found := m.entries[key]; found {
+		m.remove(element)
+	}
+}
+
+func (m *Memoized[K, V]) Stats() Stats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := m.stats
+	stats.Size = m.order.Len()
+	return stats
+}
@@ -0,0 +1,210 @@
+package memoize
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -race .
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoize(t *testing.T) {
+	var calls int
+	square := Memoize(func(number int) (int, error) {
+		calls++
+		return number * number, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		value, err := square.Get(5)
+		require.NoError(t, err)
+		assert.Equal(t, 25, value)
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Size: 1}, square.Stats())
+
+	square.Forget(5)
+	_, _ = square.Get(5)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRecursiveFibonacci(t *testing.T) {
+	var fibonacci *Memoized[int, int]
+	fibonacci = Memoize(func(n int) (int, error) {
+		if n <= 2 {
+			return 1, nil
+		}
+
+		lhs, _ := fibonacci.Get(n - 1)
+		rhs, _ := fibonacci.Get(n - 2)
+		return lhs + rhs, nil
+	})
+
+	value, err := fibonacci.Get(90)
+	require.NoError(t, err)
+	assert.Equal(t, 2880067194370816120, value)
+	assert.Equal(t, uint64(90), fibonacci.Stats().Misses)
+}
+
+func TestSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	slow := Memoize(func(key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return key + "!", nil
+	})
+
+	const callers = 10
+	wg := sync.WaitGroup{}
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			value, err := slow.Get("key")
+			assert.NoError(t, err)
+			assert.Equal(t, "key!", value)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return slow.Stats().Shared == callers-1
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTTL(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+
+	var calls int
+	memoized := Memoize(func(number int) (int, error) {
+		calls++
+		return number, nil
+	}, WithTTL(time.Minute), WithClock(c.Now))
+
+	_, _ = memoized.Get(1)
+	c.now = c.now.Add(time.Second * 59)
+	_, _ = memoized.Get(1)
+	assert.Equal(t, 1, calls)
+
+	c.now = c.now.Add(time.Second)
+	_, _ = memoized.Get(1)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMaxSize(t *testing.T) {
+	var calls []int
+	memoized := Memoize(func(number int) (int, error) {
+		calls = append(calls, number)
+		return number, nil
+	}, WithMaxSize(2))
+
+	_, _ = memoized.Get(1)
+	_, _ = memoized.Get(2)
+	_, _ = memoized.Get(1) // 2 becomes least recently used
+	_, _ = memoized.Get(3)
+	_, _ = memoized.Get(1)
+	_, _ = memoized.Get(2)
+
+	assert.Equal(t, []int{1, 2, 3, 2}, calls)
+	assert.Equal(t, uint64(2), memoized.Stats().Evictions)
+	assert.Equal(t, 2, memoized.Stats().Size)
+}
+
+func TestErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	tests := map[string]struct {
+		opts  []Option
+		calls int
+	}{
+		"errors are not cached": {
+			calls: 3,
+		},
+		"errors are cached": {
+			opts:  []Option{WithErrorCaching(time.Hour)},
+			calls: 1,
+		},
+		"zero ttl disables error caching": {
+			opts:  []Option{WithErrorCaching(0)},
+			calls: 3,
+		},
+		"negative ttl disables error caching": {
+			opts:  []Option{WithErrorCaching(-time.Second)},
+			calls: 3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			memoized := Memoize(func(key string) (int, error) {
+				calls++
+				return 0, errNotFound
+			}, test.opts...)
+
+			for i := 0; i < 3; i++ {
+				_, err := memoized.Get("key")
+				assert.ErrorIs(t, err, errNotFound)
+			}
+
+			assert.Equal(t, test.calls, calls)
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	memoized := Memoize(func(key string) (int, error) {
+		panic("boom")
+	})
+
+	assert.PanicsWithValue(t, "boom", func() { _, _ = memoized.Get("key") })
+	assert.Equal(t, 0, memoized.Stats().Size)
+}
+
+func TestPanicValueIsShared(t *testing.T) {
+	errBoom := errors.New("boom")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	memoized := Memoize(func(key string) (int, error) {
+		close(started)
+		<-release
+		panic(errBoom)
+	})
+
+	recovered := make(chan any, 1)
+	go func() {
+		defer func() { recovered <- recover() }()
+		_, _ = memoized.Get("key")
+	}()
+
+	<-started
+	go func() {
+		// wait until the second call joins the one in flight
+		for memoized.Stats().Shared == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+
+	assert.PanicsWithError(t, errBoom.Error(), func() { _, _ = memoized.Get("key") })
+	assert.Equal(t, errBoom, <-recovered)
+}
@@ -0,0 +1,249 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("parallel: panic: %v\n%s", e.Value, e.Stack)
+}
+
+type options struct {
+	workers   int
+	chunkSize int
+}
+
+type Option func(*options)
+
+func WithWorkers(workers int) Option {
+	return func(o *options) {
+		o.workers = workers
+	}
+}
+
+func WithChunkSize(chunkSize int) Option {
+	return func(o *options) {
+		o.chunkSize = chunkSize
+	}
+}
+
+func newOptions(length int, opts []Option) options {
+	o := options{workers: runtime.GOMAXPROCS(0)}
+	for _, option := range opts {
+		option(&o)
+	}
+
+	if o.workers <= 0 {
+		o.workers = 1
+	}
+
+	if o.chunkSize <= 0 {
+		// several chunks per worker to smooth out uneven work
+		o.chunkSize = (length + o.workers*4 - 1) / (o.workers * 4)
+		if o.chunkSize == 0 {
+			o.chunkSize = 1
+		}
+	}
+
+	return o
+}
+
+func (o options) chunks(length int) int {
+	return (length + o.chunkSize - 1) / o.chunkSize
+}
+
+func (o options) bounds(chunk, length int) (int, int) {
+	from := chunk * o.chunkSize
+	return from, min(from+o.chunkSize, length)
+}
+
+// run executes task for every index in [0, count) on at most workers
+// goroutines, the first error or panic cancels the remaining tasks
+func run(ctx context.Context, count, workers int, task func(context.Context, int) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(min(workers, count))
+	for i := 0; i < min(workers, count); i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				if err := safeCall(ctx, index, task); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+loop:
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func safeCall(ctx context.Context, index int, task func(context.Context, int) error) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Value: value, Stack: debug.Stack()}
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+
+	return task(ctx, index)
+}
+
+func ParallelMap[T, U any](ctx context.Context, data []T, action func(T) (U, error), opts ...Option) ([]U, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	o := newOptions(len(data), opts)
+	result := make([]U, len(data))
+	err := run(ctx, o.chunks(len(data)), o.workers, func(ctx context.Context, chunk int) error {
+		from, to := o.bounds(chunk, len(data))
+		for i := from; i < to; i++ {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+
+			value, err := action(data[i])
+			if err != nil {
+				return err
+			}
+
+			result[i] = value
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func ParallelFilter[T any](ctx context.Context, data []T, action func(T) (bool, error), opts ...Option) ([]T, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	o := newOptions(len(data), opts)
+	parts := make([][]T, o.chunks(len(data)))
+	err := run(ctx, len(parts), o.workers, func(ctx context.Context, chunk int) error {
+		from, to := o.bounds(chunk, len(data))
+		for i := from; i < to; i++ {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+
+			ok, err := action(data[i])
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				parts[chunk] = append(parts[chunk], data[i])
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+
+	result := make([]T, 0, size)
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+
+	return result, nil
+}
+
+// ParallelReduce requires action to be associative: chunks are reduced
+// independently and partial results are then combined pairwise level by
+// level, initial is applied once to the final value
+func ParallelReduce[T any](ctx context.Context, data []T, initial T, action func(T, T) (T, error), opts ...Option) (T, error) {
+	if len(data) == 0 {
+		return initial, nil
+	}
+
+	o := newOptions(len(data), opts)
+	partials := make([]T, o.chunks(len(data)))
+	err := run(ctx, len(partials), o.workers, func(ctx context.Context, chunk int) error {
+		from, to := o.bounds(chunk, len(data))
+		value := data[from]
+		for i := from + 1; i < to; i++ {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+
+			var err error
+			if value, err = action(value, data[i]); err != nil {
+				return err
+			}
+		}
+
+		partials[chunk] = value
+		return nil
+	})
+
+	for err == nil && len(partials) > 1 {
+		next := make([]T, (len(partials)+1)/2)
+		err = run(ctx, len(next), o.workers, func(_ context.Context, pair int) error {
+			lhs := 2 * pair
+			if lhs+1 == len(partials) {
+				next[pair] = partials[lhs]
+				return nil
+			}
+
+			value, err := action(partials[lhs], partials[lhs+1])
+			next[pair] = value
+			return err
+		})
+
+		partials = next
+	}
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return action(initial, partials[0])
+}
@@ -0,0 +1,198 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -race .
+
+func numbers(count int) []int {
+	data := make([]int, count)
+	for i := range data {
+		data[i] = i + 1
+	}
+
+	return data
+}
+
+func TestParallelMap(t *testing.T) {
+	tests := map[string]struct {
+		data   []int
+		opts   []Option
+		result []int
+	}{
+		"nil numbers": {},
+		"empty numbers": {
+			data:   []int{},
+			result: []int{},
+		},
+		"default options": {
+			data:   numbers(5),
+			result: []int{2, 4, 6, 8, 10},
+		},
+		"single worker": {
+			data:   numbers(5),
+			opts:   []Option{WithWorkers(1), WithChunkSize(2)},
+			result: []int{2, 4, 6, 8, 10},
+		},
+		"more workers than chunks": {
+			data:   numbers(5),
+			opts:   []Option{WithWorkers(16), WithChunkSize(3)},
+			result: []int{2, 4, 6, 8, 10},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := ParallelMap(context.Background(), test.data, func(number int) (int, error) {
+				return number * 2, nil
+			}, test.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestParallelMapOrder(t *testing.T) {
+	data := numbers(1000)
+	result, err := ParallelMap(context.Background(), data, func(number int) (int, error) {
+		if number%7 == 0 {
+			time.Sleep(time.Microsecond * 100)
+		}
+
+		return -number, nil
+	}, WithWorkers(8), WithChunkSize(10))
+
+	require.NoError(t, err)
+	for i := range data {
+		assert.Equal(t, -data[i], result[i])
+	}
+}
+
+func TestParallelMapError(t *testing.T) {
+	errBad := errors.New("bad number")
+
+	var calls atomic.Int32
+	_, err := ParallelMap(context.Background(), numbers(10000), func(number int) (int, error) {
+		calls.Add(1)
+		if number == 10 {
+			return 0, errBad
+		}
+
+		time.Sleep(time.Microsecond * 10)
+		return number, nil
+	}, WithWorkers(4), WithChunkSize(10))
+
+	assert.ErrorIs(t, err, errBad)
+	assert.Less(t, calls.Load(), int32(10000))
+}
+
+func TestParallelMapPanic(t *testing.T) {
+	_, err := ParallelMap(context.Background(), numbers(100), func(number int) (int, error) {
+		if number == 50 {
+			panic("unexpected number")
+		}
+
+		return number, nil
+	})
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "unexpected number", panicErr.Value)
+}
+
+func TestParallelMapCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParallelMap(ctx, numbers(100), func(number int) (int, error) {
+		return number, nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParallelFilter(t *testing.T) {
+	tests := map[string]struct {
+		data   []int
+		opts   []Option
+		result []int
+	}{
+		"nil numbers": {},
+		"empty numbers": {
+			data:   []int{},
+			result: []int{},
+		},
+		"even numbers": {
+			data:   numbers(10),
+			opts:   []Option{WithWorkers(3), WithChunkSize(3)},
+			result: []int{2, 4, 6, 8, 10},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := ParallelFilter(context.Background(), test.data, func(number int) (bool, error) {
+				return number%2 == 0, nil
+			}, test.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestParallelReduce(t *testing.T) {
+	tests := map[string]struct {
+		initial string
+		data    []string
+		opts    []Option
+		result  string
+	}{
+		"nil strings": {},
+		"empty strings with initial value": {
+			initial: ">",
+			data:    []string{},
+			result:  ">",
+		},
+		"concatenation keeps order": {
+			initial: ">",
+			data:    []string{"a", "b", "c", "d", "e", "f", "g"},
+			opts:    []Option{WithWorkers(3), WithChunkSize(2)},
+			result:  ">abcdefg",
+		},
+		"single chunk": {
+			data:   []string{"a", "b", "c"},
+			opts:   []Option{WithChunkSize(10)},
+			result: "abc",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := ParallelReduce(context.Background(), test.data, test.initial, func(lhs, rhs string) (string, error) {
+				return lhs + rhs, nil
+			}, test.opts...)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestParallelReduceSum(t *testing.T) {
+	result, err := ParallelReduce(context.Background(), numbers(10000), 10, func(lhs, rhs int) (int, error) {
+		return lhs + rhs, nil
+	}, WithWorkers(8), WithChunkSize(7))
+
+	require.NoError(t, err)
+	assert.Equal(t, 10+10000*10001/2, result)
+}
@@ -0,0 +1,202 @@
+package leakcheck
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGracePeriod = time.Second
+	maxRetryDelay      = 100 * time.Millisecond
+)
+
+// goroutines of the testing package itself
+var defaultIgnoredTopFunctions = []string{
+	"testing.(*T).Run",
+	"testing.(*T).Parallel",
+	"testing.(*F).Fuzz",
+	"testing.runTests.func1",
+	"testing.tRunner.func1",
+	"testing.(*M).startAlarm.func1",
+	"testing.runFuzzTests",
+	"testing.runFuzzing",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+}
+
+type Option func(*options)
+
+type options struct {
+	gracePeriod       time.Duration
+	ignoredTop        []string
+	ignoredAnywhere   []string
+	ignoredGoroutines map[int]struct{}
+}
+
+// WithGracePeriod sets how long goroutines have to finish before they are reported
+func WithGracePeriod(period time.Duration) Option {
+	return func(o *options) {
+		o.gracePeriod = period
+	}
+}
+
+// IgnoreTopFunction skips goroutines which execute the function right now
+func IgnoreTopFunction(function string) Option {
+	return func(o *options) {
+		o.ignoredTop = append(o.ignoredTop, function)
+	}
+}
+
+// IgnoreAnyFunction skips goroutines with the function anywhere in the stack
+func IgnoreAnyFunction(function string) Option {
+	return func(o *options) {
+		o.ignoredAnywhere = append(o.ignoredAnywhere, function)
+	}
+}
+
+// IgnoreCurrent skips goroutines which already exist
+func IgnoreCurrent() Option {
+	current := goroutines()
+	return func(o *options) {
+		for _, g := range current {
+			o.ignoredGoroutines[g.ID] = struct{}{}
+		}
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		gracePeriod:       defaultGracePeriod,
+		ignoredTop:        slices.Clone(defaultIgnoredTopFunctions),
+		ignoredGoroutines: make(map[int]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func (o *options) ignored(g Goroutine) bool {
+	if _, found := o.ignoredGoroutines[g.ID]; found {
+		return true
+	}
+
+	for _, function := range o.ignoredTop {
+		if g.TopFunction() == function {
+			return true
+		}
+	}
+
+	for _, function := range o.ignoredAnywhere {
+		if g.hasFunction(function) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *options) leaks() []Goroutine {
+	var leaked []Goroutine
+	for _, g := range goroutines() {
+		if !o.ignored(g) {
+			leaked = append(leaked, g)
+		}
+	}
+
+	return leaked
+}
+
+// Error lists goroutines which haven't finished during the grace period
+type Error struct {
+	Goroutines []Goroutine
+}
+
+func (e *Error) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "found %d leaked goroutines:", len(e.Goroutines))
+	for _, g := range e.Goroutines {
+		site := g.BlockingSite()
+		fmt.Fprintf(&builder, "\n\ngoroutine %d [%s] blocked in %s at %s", g.ID, g.State, site.Function, site.Location())
+		if g.CreatedBy.Function != "" {
+			fmt.Fprintf(&builder, "\ncreated by %s at %s", g.CreatedBy.Function, g.CreatedBy.Location())
+		}
+		fmt.Fprintf(&builder, "\n%s", g.Stack)
+	}
+
+	return builder.String()
+}
+
+// Find waits for unexpected goroutines to finish and returns *Error if they don't
+func Find(opts ...Option) error {
+	o := newOptions(opts)
+	return o.find()
+}
+
+func (o *options) find() error {
+	deadline := time.Now().Add(o.gracePeriod)
+	delay := time.Millisecond
+	for {
+		leaked := o.leaks()
+		if len(leaked) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return &Error{Goroutines: leaked}
+		}
+
+		runtime.Gosched()
+		time.Sleep(delay)
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
+
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+// VerifyNone remembers existing goroutines and reports new ones
+// which are still alive after the test, it must be called at
+// the beginning of a test which doesn't run in parallel
+func VerifyNone(t TestingT, opts ...Option) {
+	t.Helper()
+
+	o := newOptions(append([]Option{IgnoreCurrent()}, opts...))
+	t.Cleanup(func() {
+		if err := o.find(); err != nil {
+			t.Errorf("%v", err)
+		}
+	})
+}
+
+type TestingM interface {
+	Run() int
+}
+
+// VerifyTestMain runs tests and fails if goroutines are left after all of them
+//
+//	func TestMain(m *testing.M) {
+//		leakcheck.VerifyTestMain(m)
+//	}
+func VerifyTestMain(m TestingM, opts ...Option) {
+	o := newOptions(append([]Option{IgnoreCurrent()}, opts...))
+
+	code := m.Run()
+	if code == 0 {
+		if err := o.find(); err != nil {
+			fmt.Fprintf(os.Stderr, "leakcheck: %v\n", err)
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,198 @@
+package leakcheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+const sample = `goroutine 1 [running]:
+main.main()
+	/src/main.go:11 +0xbb
+
+goroutine 7 [chan receive, 2 minutes]:
+main.worker(0xc000012345, {0x1, 0x2})
+	/src/main.go:7 +0x19
+created by main.main in goroutine 1
+	/src/main.go:20 +0x76
+
+goroutine 8 [sleep]:
+time.Sleep(0x34630b8a000)
+	/usr/local/go/src/runtime/time.go:368 +0x165
+pkg.(*Server).loop(...)
+	/src/server.go:30
+created by pkg.(*Server).Start
+	/src/server.go:12 +0x85
+`
+
+func TestParse(t *testing.T) {
+	goroutines := parse([]byte(sample))
+	require.Len(t, goroutines, 3)
+
+	worker := goroutines[1]
+	assert.Equal(t, 7, worker.ID)
+	assert.Equal(t, "chan receive", worker.State)
+	assert.Equal(t, "main.worker", worker.TopFunction())
+	assert.Equal(t, Frame{Function: "main.worker", File: "/src/main.go", Line: 7}, worker.Frames[0])
+	assert.Equal(t, Frame{Function: "main.main", File: "/src/main.go", Line: 20}, worker.CreatedBy)
+
+	sleeper := goroutines[2]
+	assert.Equal(t, "sleep", sleeper.State)
+	assert.Equal(t, []Frame{
+		{Function: "time.Sleep", File: "/usr/local/go/src/runtime/time.go", Line: 368},
+		{Function: "pkg.(*Server).loop", File: "/src/server.go", Line: 30},
+	}, sleeper.Frames)
+	assert.Equal(t, "pkg.(*Server).Start", sleeper.CreatedBy.Function)
+}
+
+func blockForever(ch chan struct{}) {
+	<-ch
+}
+
+const elided = `goroutine 9 [chan receive]:
+main.recurse(0x0)
+	/src/deep.go:5 +0x1d
+main.recurse(0x1)
+	/src/deep.go:7 +0x2a
+...additional frames elided...
+main.recurse(0x63)
+	/src/deep.go:7 +0x2a
+main.start()
+	/src/deep.go:12 +0x25
+created by main.main
+`
+
+func TestParseSingleLineEntries(t *testing.T) {
+	goroutines := parse([]byte(elided))
+	require.Len(t, goroutines, 1)
+
+	deep := goroutines[0]
+	assert.Equal(t, []Frame{
+		{Function: "main.recurse", File: "/src/deep.go", Line: 5},
+		{Function: "main.recurse", File: "/src/deep.go", Line: 7},
+		{Function: "main.recurse", File: "/src/deep.go", Line: 7},
+		{Function: "main.start", File: "/src/deep.go", Line: 12},
+	}, deep.Frames)
+	assert.Equal(t, Frame{Function: "main.main"}, deep.CreatedBy)
+}
+
+func recurse(depth int, block <-chan struct{}) {
+	if depth == 0 {
+		<-block
+		return
+	}
+
+	recurse(depth-1, block)
+}
+
+func TestParseDeepStack(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	go recurse(200, block)
+
+	var deep Goroutine
+	require.Eventually(t, func() bool {
+		for _, g := range goroutines() {
+			if g.hasFunction("golang_course/lessons/goroutines_and_scheduler/leakcheck.recurse") && g.State == "chan receive" {
+				deep = g
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+
+	assert.Contains(t, deep.Stack, "frames elided")
+	for _, frame := range deep.Frames {
+		assert.True(t, strings.HasSuffix(frame.File, ".go"), "%+v", frame)
+		assert.Positive(t, frame.Line, "%+v", frame)
+	}
+	assert.True(t, strings.HasSuffix(deep.CreatedBy.File, "leakcheck_test.go"))
+	assert.Equal(t, "golang_course/lessons/goroutines_and_scheduler/leakcheck.TestParseDeepStack", deep.CreatedBy.Function)
+}
+
+func TestFindLeak(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+
+	opts := []Option{IgnoreCurrent(), WithGracePeriod(50 * time.Millisecond)}
+	go blockForever(ch)
+
+	start := time.Now()
+	err := Find(opts...)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	var leakErr *Error
+	require.ErrorAs(t, err, &leakErr)
+	require.Len(t, leakErr.Goroutines, 1)
+
+	leaked := leakErr.Goroutines[0]
+	assert.Equal(t, "chan receive", leaked.State)
+	assert.Equal(t, "golang_course/lessons/goroutines_and_scheduler/leakcheck.blockForever", leaked.BlockingSite().Function)
+	assert.True(t, strings.HasSuffix(leaked.BlockingSite().File, "leakcheck_test.go"))
+	assert.Contains(t, err.Error(), "found 1 leaked goroutines")
+	assert.Contains(t, err.Error(), "blocked in golang_course/lessons/goroutines_and_scheduler/leakcheck.blockForever")
+
+	assert.NoError(t, Find(append(opts, IgnoreTopFunction(leaked.TopFunction()))...))
+	assert.NoError(t, Find(append(opts, IgnoreAnyFunction(leaked.TopFunction()))...))
+}
+
+func TestGracePeriod(t *testing.T) {
+	opts := []Option{IgnoreCurrent(), WithGracePeriod(time.Second)}
+	go time.Sleep(100 * time.Millisecond)
+
+	assert.NoError(t, Find(opts...))
+}
+
+type fakeT struct {
+	cleanups []func()
+	errors   []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestVerifyNone(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+
+	// existing goroutines are not reported
+	go blockForever(ch)
+
+	fake := &fakeT{}
+	VerifyNone(fake, WithGracePeriod(10*time.Millisecond))
+	done := make(chan struct{})
+	go func() { <-done }()
+	go blockForever(ch)
+
+	close(done)
+	require.Len(t, fake.cleanups, 1)
+	fake.cleanups[0]()
+
+	require.Len(t, fake.errors, 1)
+	assert.Contains(t, fake.errors[0], "found 1 leaked goroutines")
+}
+
+func TestVerifyNoneWithoutLeaks(t *testing.T) {
+	VerifyNone(t)
+
+	done := make(chan struct{})
+	go func() { close(done) }()
+	<-done
+}
+
+func TestMain(m *testing.M) {
+	VerifyTestMain(m)
+}
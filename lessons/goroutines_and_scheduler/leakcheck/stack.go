@@ -0,0 +1,164 @@
+package leakcheck
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) Location() string {
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+type Goroutine struct {
+	ID        int
+	State     string // like "chan receive" or "select"
+	Frames    []Frame
+	CreatedBy Frame
+	Stack     string
+}
+
+// TopFunction returns the function which is executed right now
+func (g Goroutine) TopFunction() string {
+	if len(g.Frames) == 0 {
+		return ""
+	}
+
+	return g.Frames[0].Function
+}
+
+// BlockingSite returns the first frame outside of the standard library,
+// it points to the line of the code which waits forever
+func (g Goroutine) BlockingSite() Frame {
+	root := runtime.GOROOT()
+	for _, frame := range g.Frames {
+		if root == "" || !strings.HasPrefix(frame.File, root) {
+			return frame
+		}
+	}
+
+	if len(g.Frames) == 0 {
+		return Frame{}
+	}
+
+	return g.Frames[0]
+}
+
+func (g Goroutine) hasFunction(function string) bool {
+	for _, frame := range g.Frames {
+		if frame.Function == function {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goroutines returns stacks of all goroutines except the current one
+func goroutines() []Goroutine {
+	buffer := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) {
+			buffer = buffer[:n]
+			break
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+
+	all := parse(buffer)
+	return all[1:] // the current goroutine goes first
+}
+
+// parse reads the format of runtime.Stack:
+//
+//	goroutine 7 [chan receive, 2 minutes]:
+//	main.worker(0xc000012345)
+//		/path/main.go:12 +0x19
+//	created by main.main in goroutine 1
+//		/path/main.go:20 +0x76
+//
+// deep stacks have a line like "...additional frames elided..." without location
+func parse(data []byte) []Goroutine {
+	var result []Goroutine
+	for _, block := range bytes.Split(bytes.TrimSpace(data), []byte("\n\n")) {
+		if g, ok := parseGoroutine(string(block)); ok {
+			result = append(result, g)
+		}
+	}
+
+	return result
+}
+
+func parseGoroutine(block string) (Goroutine, bool) {
+	lines := strings.Split(block, "\n")
+	header, found := strings.CutPrefix(lines[0], "goroutine ")
+	if !found {
+		return Goroutine{}, false
+	}
+
+	id, state, found := strings.Cut(header, " [")
+	if !found {
+		return Goroutine{}, false
+	}
+
+	g := Goroutine{Stack: block}
+	g.ID, _ = strconv.Atoi(id)
+	state = strings.TrimSuffix(state, "]:")
+	g.State, _, _ = strings.Cut(state, ",")
+
+	// locations are indented, some entries go without them
+	for i := 1; i < len(lines); i++ {
+		entry := lines[i]
+		var frame Frame
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			i++
+			frame = parseLocation(lines[i])
+		}
+
+		if strings.HasPrefix(entry, "...") {
+			continue
+		}
+
+		if creator, found := strings.CutPrefix(entry, "created by "); found {
+			frame.Function, _, _ = strings.Cut(creator, " in goroutine")
+			g.CreatedBy = frame
+			continue
+		}
+
+		frame.Function = trimArguments(entry)
+		g.Frames = append(g.Frames, frame)
+	}
+
+	return g, true
+}
+
+func trimArguments(function string) string {
+	if strings.HasSuffix(function, ")") {
+		if index := strings.LastIndex(function, "("); index > 0 {
+			return function[:index]
+		}
+	}
+
+	return function
+}
+
+func parseLocation(line string) Frame {
+	line = strings.TrimSpace(line)
+	line, _, _ = strings.Cut(line, " +0x")
+
+	index := strings.LastIndex(line, ":")
+	if index < 0 {
+		return Frame{File: line}
+	}
+
+	number, _ := strconv.Atoi(line[index+1:])
+	return Frame{File: line[:index], Line: number}
+}
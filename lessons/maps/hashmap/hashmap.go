@@ -0,0 +1,453 @@
+package hashmap
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+const (
+	bucketCount = 8
+
+	// the average number of entries per bucket that triggers growth is 6.5
+	loadFactorNum = 13
+	loadFactorDen = 2
+
+	// possible tophash values, real hashes start from minTopHash
+	emptyRest      = 0 // this and all following slots of the chain are empty
+	emptyOne       = 1 // this slot is empty
+	evacuatedX     = 2 // the entry moved to the first half of the new array
+	evacuatedY     = 3 // the entry moved to the second half of the new array
+	evacuatedEmpty = 4 // the slot was empty when the bucket was evacuated
+	minTopHash     = 5
+)
+
+type bucket[K comparable, V any] struct {
+	tophash  [bucketCount]uint8
+	keys     [bucketCount]K
+	values   [bucketCount]V
+	overflow *bucket[K, V]
+}
+
+func (b *bucket[K, V]) evacuated() bool {
+	top := b.tophash[0]
+	return top > emptyOne && top < minTopHash
+}
+
+func isEmpty(top uint8) bool {
+	return top <= emptyOne
+}
+
+// Map mirrors the bucket-based runtime map used before Go 1.24:
+// 2^B buckets of 8 slots with overflow chains, and incremental
+// evacuation to a new array while the map grows, the zero value
+// is an empty map ready to use
+type Map[K comparable, V any] struct {
+	count     int
+	B         uint8
+	noverflow int
+	seed      maphash.Seed
+
+	buckets    []bucket[K, V]
+	oldbuckets []bucket[K, V] // not nil while growing
+	nevacuate  int            // buckets below this index are evacuated
+	sameSize   bool           // growth only compacts overflow buckets
+}
+
+// New preallocates buckets for hint entries like make(map[K]V, hint)
+func New[K comparable, V any](hint int) *Map[K, V] {
+	m := &Map[K, V]{seed: maphash.MakeSeed()}
+	for overLoadFactor(hint, m.B) {
+		m.B++
+	}
+
+	if hint > 0 {
+		m.buckets = make([]bucket[K, V], 1<<m.B)
+	}
+
+	return m
+}
+
+func overLoadFactor(count int, B uint8) bool {
+	return count > bucketCount && count > loadFactorNum*((1<<B)/loadFactorDen)
+}
+
+func tooManyOverflowBuckets(noverflow int, B uint8) bool {
+	return noverflow >= 1<<min(B, 15)
+}
+
+func (m *Map[K, V]) hash(key K) uint64 {
+	return maphash.Comparable(m.seed, key)
+}
+
+func tophash(hash uint64) uint8 {
+	top := uint8(hash >> 56)
+	if top < minTopHash {
+		top += minTopHash
+	}
+
+	return top
+}
+
+func (m *Map[K, V]) bucketMask() uint64 {
+	return 1<<m.B - 1
+}
+
+func (m *Map[K, V]) growing() bool {
+	return m.oldbuckets != nil
+}
+
+func (m *Map[K, V]) oldBucketMask() uint64 {
+	return uint64(len(m.oldbuckets) - 1)
+}
+
+func (m *Map[K, V]) Len() int {
+	return m.count
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if m.count == 0 {
+		return zero, false
+	}
+
+	hash := m.hash(key)
+	b := &m.buckets[hash&m.bucketMask()]
+	if m.growing() {
+		if old := &m.oldbuckets[hash&m.oldBucketMask()]; !old.evacuated() {
+			b = old
+		}
+	}
+
+	top := tophash(hash)
+	for ; b != nil; b = b.overflow {
+		for i := 0; i < bucketCount; i++ {
+			if b.tophash[i] != top {
+				if b.tophash[i] == emptyRest {
+					return zero, false
+				}
+				continue
+			}
+
+			if b.keys[i] == key {
+				return b.values[i], true
+			}
+		}
+	}
+
+	return zero, false
+}
+
+func (m *Map[K, V]) Set(key K, value V) {
+	// the zero value map gets its seed with the first bucket
+	if m.buckets == nil {
+		m.seed = maphash.MakeSeed()
+		m.buckets = make([]bucket[K, V], 1)
+	}
+
+	hash := m.hash(key)
+	top := tophash(hash)
+
+again:
+	index := hash & m.bucketMask()
+	if m.growing() {
+		m.growWork(index)
+	}
+
+	var insertBucket *bucket[K, V]
+	var insertIndex int
+
+	b := &m.buckets[index]
+search:
+	for {
+		for i := 0; i < bucketCount; i++ {
+			if b.tophash[i] != top {
+				if isEmpty(b.tophash[i]) && insertBucket == nil {
+					insertBucket, insertIndex = b, i
+				}
+
+				if b.tophash[i] == emptyRest {
+					break search
+				}
+				continue
+			}
+
+			if b.keys[i] == key {
+				b.values[i] = value
+				return
+			}
+		}
+
+		if b.overflow == nil {
+			break
+		}
+
+		b = b.overflow
+	}
+
+	// start growing only when a new entry is inserted, the current
+	// bucket may move during evacuation so the search starts again
+	if !m.growing() && (overLoadFactor(m.count+1, m.B) || tooManyOverflowBuckets(m.noverflow, m.B)) {
+		m.hashGrow()
+		goto again
+	}
+
+	if insertBucket == nil {
+		insertBucket = &bucket[K, V]{}
+		b.overflow = insertBucket
+		m.noverflow++
+	}
+
+	insertBucket.tophash[insertIndex] = top
+	insertBucket.keys[insertIndex] = key
+	insertBucket.values[insertIndex] = value
+	m.count++
+}
+
+func (m *Map[K, V]) Delete(key K) {
+	if m.count == 0 {
+		return
+	}
+
+	hash := m.hash(key)
+	index := hash & m.bucketMask()
+	if m.growing() {
+		m.growWork(index)
+	}
+
+	top := tophash(hash)
+	head := &m.buckets[index]
+	for b := head; b != nil; b = b.overflow {
+		for i := 0; i < bucketCount; i++ {
+			if b.tophash[i] != top {
+				if b.tophash[i] == emptyRest {
+					return
+				}
+				continue
+			}
+
+			if b.keys[i] != key {
+				continue
+			}
+
+			var zeroKey K
+			var zeroValue V
+			b.keys[i], b.values[i] = zeroKey, zeroValue
+			b.tophash[i] = emptyOne
+			m.markEmptyRest(head, b, i)
+			m.count--
+			return
+		}
+	}
+}
+
+// markEmptyRest turns trailing emptyOne slots into emptyRest,
+// so lookups of missing keys can stop early
+func (m *Map[K, V]) markEmptyRest(head, b *bucket[K, V], i int) {
+	if i == bucketCount-1 {
+		if b.overflow != nil && b.overflow.tophash[0] != emptyRest {
+			return
+		}
+	} else if b.tophash[i+1] != emptyRest {
+		return
+	}
+
+	for {
+		b.tophash[i] = emptyRest
+		if i == 0 {
+			if b == head {
+				return
+			}
+
+			previous := head
+			for previous.overflow != b {
+				previous = previous.overflow
+			}
+
+			b, i = previous, bucketCount-1
+		} else {
+			i--
+		}
+
+		if b.tophash[i] != emptyOne {
+			return
+		}
+	}
+}
+
+func (m *Map[K, V]) Clear() {
+	*m = Map[K, V]{seed: maphash.MakeSeed()}
+}
+
+func (m *Map[K, V]) hashGrow() {
+	bigger := uint8(1)
+	m.sameSize = false
+	if !overLoadFactor(m.count+1, m.B) {
+		bigger = 0
+		m.sameSize = true
+	}
+
+	m.oldbuckets = m.buckets
+	m.buckets = make([]bucket[K, V], 1<<(m.B+bigger))
+	m.B += bigger
+	m.nevacuate = 0
+	m.noverflow = 0
+}
+
+// growWork evacuates the old bucket that is about to be used
+// and one more bucket to make progress
+func (m *Map[K, V]) growWork(index uint64) {
+	m.evacuate(int(index & m.oldBucketMask()))
+	if m.growing() {
+		m.evacuate(m.nevacuate)
+	}
+}
+
+type destination[K comparable, V any] struct {
+	b *bucket[K, V]
+	i int
+}
+
+func (m *Map[K, V]) evacuate(oldIndex int) {
+	old := &m.oldbuckets[oldIndex]
+	newbit := len(m.oldbuckets)
+
+	if !old.evacuated() {
+		// X is the bucket with the same index, Y is the one in the second half
+		targets := [2]destination[K, V]{{b: &m.buckets[oldIndex]}}
+		if !m.sameSize {
+			targets[1] = destination[K, V]{b: &m.buckets[oldIndex+newbit]}
+		}
+
+		for b := old; b != nil; b = b.overflow {
+			for i := 0; i < bucketCount; i++ {
+				top := b.tophash[i]
+				if isEmpty(top) {
+					b.tophash[i] = evacuatedEmpty
+					continue
+				}
+
+				y := 0
+				if !m.sameSize && m.hash(b.keys[i])&uint64(newbit) != 0 {
+					y = 1
+				}
+
+				b.tophash[i] = evacuatedX + uint8(y)
+				target := &targets[y]
+				if target.i == bucketCount {
+					overflow := &bucket[K, V]{}
+					target.b.overflow = overflow
+					target.b, target.i = overflow, 0
+					m.noverflow++
+				}
+
+				target.b.tophash[target.i] = top
+				target.b.keys[target.i] = b.keys[i]
+				target.b.values[target.i] = b.values[i]
+				target.i++
+			}
+		}
+
+		// only tophash of the first bucket is needed to know it was evacuated
+		old.keys, old.values, old.overflow = [bucketCount]K{}, [bucketCount]V{}, nil
+	}
+
+	if oldIndex == m.nevacuate {
+		m.advanceEvacuationMark()
+	}
+}
+
+func (m *Map[K, V]) advanceEvacuationMark() {
+	m.nevacuate++
+	stop := min(m.nevacuate+1024, len(m.oldbuckets))
+	for m.nevacuate != stop && m.oldbuckets[m.nevacuate].evacuated() {
+		m.nevacuate++
+	}
+
+	if m.nevacuate == len(m.oldbuckets) {
+		m.oldbuckets = nil
+		m.sameSize = false
+	}
+}
+
+// All iterates over entries, the map must not be modified during iteration
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for index := range m.buckets {
+			b := &m.buckets[index]
+			check := false
+			if m.growing() {
+				if old := &m.oldbuckets[uint64(index)&m.oldBucketMask()]; !old.evacuated() {
+					// entries of an old bucket are split between two new buckets,
+					// only those belonging to the current one are visited
+					b, check = old, !m.sameSize
+				}
+			}
+
+			for ; b != nil; b = b.overflow {
+				for i := 0; i < bucketCount; i++ {
+					if isEmpty(b.tophash[i]) {
+						continue
+					}
+
+					if check && m.hash(b.keys[i])&m.bucketMask() != uint64(index) {
+						continue
+					}
+
+					if !yield(b.keys[i], b.values[i]) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+type Stats struct {
+	Count           int
+	B               uint8
+	Buckets         int
+	OverflowBuckets int
+	LoadFactor      float64
+	Growing         bool
+	SameSizeGrow    bool
+	OldBuckets      int
+	Evacuated       int // evacuated old buckets
+	NEvacuate       int // all old buckets below this index are evacuated
+}
+
+func (m *Map[K, V]) Stats() Stats {
+	stats := Stats{
+		Count:           m.count,
+		B:               m.B,
+		Buckets:         len(m.buckets),
+		OverflowBuckets: m.noverflow,
+		Growing:         m.growing(),
+		SameSizeGrow:    m.sameSize,
+		OldBuckets:      len(m.oldbuckets),
+		NEvacuate:       m.nevacuate,
+	}
+
+	if stats.Buckets > 0 {
+		stats.LoadFactor = float64(m.count) / float64(stats.Buckets)
+	}
+
+	for i := range m.oldbuckets {
+		if m.oldbuckets[i].evacuated() {
+			stats.Evacuated++
+		}
+	}
+
+	return stats
+}
+
+// ChainLengths returns the number of buckets in the chain of every bucket
+func (m *Map[K, V]) ChainLengths() []int {
+	lengths := make([]int, len(m.buckets))
+	for i := range m.buckets {
+		for b := &m.buckets[i]; b != nil; b = b.overflow {
+			lengths[i]++
+		}
+	}
+
+	return lengths
+}
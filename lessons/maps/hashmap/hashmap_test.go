@@ -0,0 +1,208 @@
+package hashmap
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func TestMapOperations(t *testing.T) {
+	m := New[string, int](0)
+	_, found := m.Get("missing")
+	assert.False(t, found)
+
+	m.Set("one", 1)
+	m.Set("two", 2)
+	m.Set("one", 10)
+	assert.Equal(t, 2, m.Len())
+
+	value, found := m.Get("one")
+	assert.True(t, found)
+	assert.Equal(t, 10, value)
+
+	m.Delete("one")
+	m.Delete("missing")
+	_, found = m.Get("one")
+	assert.False(t, found)
+	assert.Equal(t, 1, m.Len())
+
+	m.Clear()
+	assert.Zero(t, m.Len())
+	_, found = m.Get("two")
+	assert.False(t, found)
+}
+
+func TestZeroValueMap(t *testing.T) {
+	var m Map[string, int]
+	_, found := m.Get("missing")
+	assert.False(t, found)
+	m.Delete("missing")
+	assert.Zero(t, m.Stats())
+	assert.Empty(t, m.ChainLengths())
+	for range m.All() {
+		t.Fatal("empty map has entries")
+	}
+
+	for i := 0; i < 100; i++ {
+		m.Set(string(rune('a'+i)), i)
+	}
+
+	assert.NotZero(t, m.seed)
+	assert.Equal(t, 100, m.Len())
+	value, found := m.Get("b")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	m.Delete("b")
+	_, found = m.Get("b")
+	assert.False(t, found)
+	assert.Equal(t, 99, m.Len())
+}
+
+func TestMapMatchesBuiltin(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	m := New[int, int](0)
+	expected := make(map[int]int)
+
+	for i := 0; i < 100_000; i++ {
+		key := r.Intn(20_000)
+		switch r.Intn(3) {
+		case 0, 1:
+			m.Set(key, i)
+			expected[key] = i
+		case 2:
+			m.Delete(key)
+			delete(expected, key)
+		}
+
+		if i%1000 == 0 {
+			key := r.Intn(20_000)
+			value, found := m.Get(key)
+			expectedValue, expectedFound := expected[key]
+			require.Equal(t, expectedFound, found)
+			require.Equal(t, expectedValue, value)
+		}
+	}
+
+	require.Equal(t, len(expected), m.Len())
+	for key, value := range expected {
+		actual, found := m.Get(key)
+		require.True(t, found)
+		require.Equal(t, value, actual)
+	}
+}
+
+func TestIncrementalGrowth(t *testing.T) {
+	m := New[int, int](0)
+	for i := 0; ; i++ {
+		m.Set(i, i)
+		if m.Stats().Growing {
+			break
+		}
+	}
+
+	stats := m.Stats()
+	assert.False(t, stats.SameSizeGrow)
+	assert.Equal(t, 2*stats.OldBuckets, stats.Buckets)
+	assert.Less(t, stats.Evacuated, stats.OldBuckets)
+
+	// iteration must see every entry exactly once while growing
+	seen := make(map[int]int)
+	for key, value := range m.All() {
+		assert.Equal(t, key, value)
+		seen[key]++
+	}
+	assert.Len(t, seen, m.Len())
+	for _, count := range seen {
+		assert.Equal(t, 1, count)
+	}
+
+	// every write moves evacuation forward
+	for i := 0; m.Stats().Growing; i++ {
+		m.Set(i, i)
+	}
+
+	stats = m.Stats()
+	assert.Zero(t, stats.OldBuckets)
+	assert.LessOrEqual(t, stats.LoadFactor, 6.5)
+	for i := 0; i < m.Len(); i++ {
+		value, found := m.Get(i)
+		assert.True(t, found)
+		assert.Equal(t, i, value)
+	}
+}
+
+func TestPreallocation(t *testing.T) {
+	m := New[int, int](1000)
+	buckets := m.Stats().Buckets
+	assert.Equal(t, 256, buckets)
+
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+
+	assert.Equal(t, buckets, m.Stats().Buckets)
+	assert.False(t, m.Stats().Growing)
+}
+
+func TestChainLengths(t *testing.T) {
+	m := New[int, int](0)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+
+	total := 0
+	for _, length := range m.ChainLengths() {
+		assert.GreaterOrEqual(t, length, 1)
+		total += length
+	}
+
+	stats := m.Stats()
+	assert.Equal(t, stats.Buckets+stats.OverflowBuckets, total)
+}
+
+func TestAllStopsEarly(t *testing.T) {
+	m := New[int, int](0)
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 10 {
+			break
+		}
+	}
+
+	assert.Equal(t, 10, count)
+}
+
+var Result int
+
+func BenchmarkMapSet(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		m := New[int, int](0)
+		for j := 0; j < 10_000; j++ {
+			m.Set(j, j)
+		}
+		Result = m.Len()
+	}
+}
+
+func BenchmarkMapGet(b *testing.B) {
+	m := New[int, int](0)
+	for j := 0; j < 10_000; j++ {
+		m.Set(j, j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		value, _ := m.Get(i % 10_000)
+		Result = value
+	}
+}
@@ -0,0 +1,111 @@
+package swiss
+
+import (
+	"testing"
+
+	"golang_course/lessons/maps/hashmap"
+)
+
+// go test -bench=. -benchmem .
+
+var Result int
+
+// the same workload as in map_performance
+func BenchmarkSmallBuiltin(b *testing.B) {
+	table := map[int]int{0: 1, 1: 2, 2: 3}
+	for i := 0; i < b.N; i++ {
+		value := table[1]
+		table[1] = value
+	}
+}
+
+func BenchmarkSmallBucket(b *testing.B) {
+	table := hashmap.New[int, int](0)
+	table.Set(0, 1)
+	table.Set(1, 2)
+	table.Set(2, 3)
+	for i := 0; i < b.N; i++ {
+		value, _ := table.Get(1)
+		table.Set(1, value)
+	}
+}
+
+func BenchmarkSmallSwiss(b *testing.B) {
+	table := New[int, int](0)
+	table.Set(0, 1)
+	table.Set(1, 2)
+	table.Set(2, 3)
+	for i := 0; i < b.N; i++ {
+		value, _ := table.Get(1)
+		table.Set(1, value)
+	}
+}
+
+const entries = 100_000
+
+func BenchmarkInsertBuiltin(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		table := make(map[int]int)
+		for j := 0; j < entries; j++ {
+			table[j] = j
+		}
+		Result = len(table)
+	}
+}
+
+func BenchmarkInsertBucket(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		table := hashmap.New[int, int](0)
+		for j := 0; j < entries; j++ {
+			table.Set(j, j)
+		}
+		Result = table.Len()
+	}
+}
+
+func BenchmarkInsertSwiss(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		table := New[int, int](0)
+		for j := 0; j < entries; j++ {
+			table.Set(j, j)
+		}
+		Result = table.Len()
+	}
+}
+
+func BenchmarkLookupBuiltin(b *testing.B) {
+	table := make(map[int]int)
+	for j := 0; j < entries; j++ {
+		table[j] = j
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// every second lookup misses
+		Result = table[i%(2*entries)]
+	}
+}
+
+func BenchmarkLookupBucket(b *testing.B) {
+	table := hashmap.New[int, int](0)
+	for j := 0; j < entries; j++ {
+		table.Set(j, j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result, _ = table.Get(i % (2 * entries))
+	}
+}
+
+func BenchmarkLookupSwiss(b *testing.B) {
+	table := New[int, int](0)
+	for j := 0; j < entries; j++ {
+		table.Set(j, j)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result, _ = table.Get(i % (2 * entries))
+	}
+}
@@ -0,0 +1,367 @@
+package swiss
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+)
+
+const (
+	groupSize = 8
+
+	// table is resized when it is filled by 7/8
+	maxLoadNum = 7
+	maxLoadDen = 8
+
+	// control byte of a full slot stores 7 bits of the hash (H2),
+	// special values have the highest bit set
+	ctrlEmpty   = 0b1000_0000
+	ctrlDeleted = 0b1111_1110
+
+	lsbs = 0x0101010101010101
+	msbs = 0x8080808080808080
+)
+
+// bitset marks slots of a group by the highest bit of their byte
+type bitset uint64
+
+func (b bitset) first() int {
+	return bits.TrailingZeros64(uint64(b)) / 8
+}
+
+func (b bitset) removeFirst() bitset {
+	return b & (b - 1)
+}
+
+// ctrl holds control bytes of the whole group in one word,
+// so all slots are checked at once without SIMD instructions
+type ctrl uint64
+
+func (c ctrl) get(i int) uint8 {
+	return uint8(c >> (8 * i))
+}
+
+func (c *ctrl) set(i int, value uint8) {
+	shift := 8 * i
+	*c = *c&^(0xFF<<shift) | ctrl(value)<<shift
+}
+
+// matchH2 can report false positives, keys are compared anyway
+func (c ctrl) matchH2(h2 uint8) bitset {
+	v := uint64(c) ^ (lsbs * uint64(h2))
+	return bitset((v - lsbs) &^ v & msbs)
+}
+
+// only empty has the highest bit set and the second lowest cleared
+func (c ctrl) matchEmpty() bitset {
+	return bitset(uint64(c) & (^uint64(c) << 6) & msbs)
+}
+
+func (c ctrl) matchEmptyOrDeleted() bitset {
+	return bitset(uint64(c) & msbs)
+}
+
+func (c ctrl) matchFull() bitset {
+	return bitset(^uint64(c) & msbs)
+}
+
+type group[K comparable, V any] struct {
+	ctrl   ctrl
+	keys   [groupSize]K
+	values [groupSize]V
+}
+
+func newGroups[K comparable, V any](count int) []group[K, V] {
+	groups := make([]group[K, V], count)
+	for i := range groups {
+		groups[i].ctrl = ctrlEmpty * lsbs
+	}
+
+	return groups
+}
+
+// Map is an open-addressing hash table in the style of Swiss tables:
+// slots are probed by groups of 8 and control bytes filter out
+// most of the slots before keys are compared, the zero value is
+// an empty map ready to use
+type Map[K comparable, V any] struct {
+	seed       maphash.Seed
+	groups     []group[K, V]
+	count      int
+	tombstones int
+	growthLeft int // inserts into empty slots before resize
+}
+
+// New preallocates slots for hint entries like make(map[K]V, hint)
+func New[K comparable, V any](hint int) *Map[K, V] {
+	m := &Map[K, V]{seed: maphash.MakeSeed()}
+	m.reset(groupsFor(hint))
+	return m
+}
+
+func groupsFor(entries int) int {
+	groups := 1
+	for groups*groupSize*maxLoadNum/maxLoadDen < entries {
+		groups *= 2
+	}
+
+	return groups
+}
+
+// init allocates the table of the zero value map on the first insert
+func (m *Map[K, V]) init() {
+	if len(m.groups) == 0 {
+		m.seed = maphash.MakeSeed()
+		m.reset(1)
+	}
+}
+
+func (m *Map[K, V]) reset(groups int) {
+	m.groups = newGroups[K, V](groups)
+	m.count, m.tombstones = 0, 0
+	m.growthLeft = groups * groupSize * maxLoadNum / maxLoadDen
+}
+
+// hash is split into H1 to choose the group and H2 for control bytes
+func (m *Map[K, V]) hash(key K) (uint64, uint8) {
+	hash := maphash.Comparable(m.seed, key)
+	return hash >> 7, uint8(hash & 0x7F)
+}
+
+// probe is quadratic over groups, with the power of two number
+// of groups triangular steps visit every group exactly once
+type probe struct {
+	mask   uint64
+	offset uint64
+	index  uint64
+}
+
+func newProbe(h1 uint64, groups int) probe {
+	mask := uint64(groups - 1)
+	return probe{mask: mask, offset: h1 & mask}
+}
+
+func (p *probe) next() {
+	p.index++
+	p.offset = (p.offset + p.index) & p.mask
+}
+
+func (m *Map[K, V]) Len() int {
+	return m.count
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if m.count == 0 {
+		var zero V
+		return zero, false
+	}
+
+	h1, h2 := m.hash(key)
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.offset]
+		for match := g.ctrl.matchH2(h2); match != 0; match = match.removeFirst() {
+			if i := match.first(); g.keys[i] == key {
+				return g.values[i], true
+			}
+		}
+
+		// the key would have been placed into the empty slot
+		if g.ctrl.matchEmpty() != 0 {
+			var zero V
+			return zero, false
+		}
+	}
+}
+
+func (m *Map[K, V]) Set(key K, value V) {
+	m.init()
+	h1, h2 := m.hash(key)
+
+	var target *group[K, V]
+	var targetIndex int
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.offset]
+		for match := g.ctrl.matchH2(h2); match != 0; match = match.removeFirst() {
+			if i := match.first(); g.keys[i] == key {
+				g.values[i] = value
+				return
+			}
+		}
+
+		if target == nil {
+			if match := g.ctrl.matchEmptyOrDeleted(); match != 0 {
+				target, targetIndex = g, match.first()
+			}
+		}
+
+		if g.ctrl.matchEmpty() != 0 {
+			break
+		}
+	}
+
+	if target.ctrl.get(targetIndex) == ctrlEmpty {
+		if m.growthLeft == 0 {
+			m.rehash()
+			m.Set(key, value)
+			return
+		}
+
+		m.growthLeft--
+	} else {
+		m.tombstones--
+	}
+
+	target.ctrl.set(targetIndex, h2)
+	target.keys[targetIndex] = key
+	target.values[targetIndex] = value
+	m.count++
+}
+
+func (m *Map[K, V]) Delete(key K) {
+	if m.count == 0 {
+		return
+	}
+
+	h1, h2 := m.hash(key)
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.offset]
+		for match := g.ctrl.matchH2(h2); match != 0; match = match.removeFirst() {
+			i := match.first()
+			if g.keys[i] != key {
+				continue
+			}
+
+			var zeroKey K
+			var zeroValue V
+			g.keys[i], g.values[i] = zeroKey, zeroValue
+
+			// a group with an empty slot has never been full, so no probe
+			// sequence went through it and the slot can become empty again
+			if g.ctrl.matchEmpty() != 0 {
+				g.ctrl.set(i, ctrlEmpty)
+				m.growthLeft++
+			} else {
+				g.ctrl.set(i, ctrlDeleted)
+				m.tombstones++
+			}
+
+			m.count--
+			return
+		}
+
+		if g.ctrl.matchEmpty() != 0 {
+			return
+		}
+	}
+}
+
+func (m *Map[K, V]) Clear() {
+	if len(m.groups) == 0 {
+		return
+	}
+
+	m.seed = maphash.MakeSeed()
+	m.reset(len(m.groups))
+}
+
+// rehash doubles the table, or only drops tombstones
+// if they take a significant part of the table
+func (m *Map[K, V]) rehash() {
+	groups := len(m.groups)
+	capacity := groups * groupSize
+	if m.count > capacity*maxLoadNum/maxLoadDen/2 {
+		groups *= 2
+	}
+
+	old := m.groups
+	m.reset(groups)
+	for i := range old {
+		g := &old[i]
+		for match := g.ctrl.matchFull(); match != 0; match = match.removeFirst() {
+			j := match.first()
+			m.insertNew(g.keys[j], g.values[j])
+		}
+	}
+}
+
+// insertNew places a key known to be absent without checking for duplicates
+func (m *Map[K, V]) insertNew(key K, value V) {
+	h1, h2 := m.hash(key)
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.offset]
+		if match := g.ctrl.matchEmpty(); match != 0 {
+			i := match.first()
+			g.ctrl.set(i, h2)
+			g.keys[i] = key
+			g.values[i] = value
+			m.growthLeft--
+			m.count++
+			return
+		}
+	}
+}
+
+// All iterates over entries, the map must not be modified during iteration
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for i := range m.groups {
+			g := &m.groups[i]
+			for match := g.ctrl.matchFull(); match != 0; match = match.removeFirst() {
+				j := match.first()
+				if !yield(g.keys[j], g.values[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+type Stats struct {
+	Count      int
+	Groups     int
+	Capacity   int
+	Tombstones int
+	GrowthLeft int
+	LoadFactor float64
+}
+
+func (m *Map[K, V]) Stats() Stats {
+	capacity := len(m.groups) * groupSize
+	stats := Stats{
+		Count:      m.count,
+		Groups:     len(m.groups),
+		Capacity:   capacity,
+		Tombstones: m.tombstones,
+		GrowthLeft: m.growthLeft,
+	}
+
+	if capacity > 0 {
+		stats.LoadFactor = float64(m.count) / float64(capacity)
+	}
+
+	return stats
+}
+
+// ProbeLength returns the number of groups visited to find the key
+func (m *Map[K, V]) ProbeLength(key K) int {
+	if len(m.groups) == 0 {
+		return 0
+	}
+
+	h1, h2 := m.hash(key)
+	length := 1
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.offset]
+		for match := g.ctrl.matchH2(h2); match != 0; match = match.removeFirst() {
+			if g.keys[match.first()] == key {
+				return length
+			}
+		}
+
+		if g.ctrl.matchEmpty() != 0 {
+			return length
+		}
+
+		length++
+	}
+}
@@ -0,0 +1,177 @@
+package swiss
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func TestControlMatching(t *testing.T) {
+	var c ctrl = ctrlEmpty * lsbs
+	c.set(1, 0x15)
+	c.set(3, ctrlDeleted)
+	c.set(6, 0x15)
+	c.set(7, 0x7F)
+
+	collect := func(b bitset) []int {
+		var slots []int
+		for ; b != 0; b = b.removeFirst() {
+			slots = append(slots, b.first())
+		}
+		return slots
+	}
+
+	tests := map[string]struct {
+		match    bitset
+		expected []int
+	}{
+		"h2":                 {match: c.matchH2(0x15), expected: []int{1, 6}},
+		"empty":              {match: c.matchEmpty(), expected: []int{0, 2, 4, 5}},
+		"empty or deleted":   {match: c.matchEmptyOrDeleted(), expected: []int{0, 2, 3, 4, 5}},
+		"full":               {match: c.matchFull(), expected: []int{1, 6, 7}},
+		"h2 without matches": {match: c.matchH2(0x01), expected: nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, collect(test.match))
+		})
+	}
+}
+
+func TestMapOperations(t *testing.T) {
+	m := New[string, int](0)
+	_, found := m.Get("missing")
+	assert.False(t, found)
+
+	m.Set("one", 1)
+	m.Set("two", 2)
+	m.Set("one", 10)
+	assert.Equal(t, 2, m.Len())
+
+	value, found := m.Get("one")
+	assert.True(t, found)
+	assert.Equal(t, 10, value)
+
+	m.Delete("one")
+	m.Delete("missing")
+	_, found = m.Get("one")
+	assert.False(t, found)
+	assert.Equal(t, 1, m.Len())
+
+	m.Clear()
+	assert.Zero(t, m.Len())
+	_, found = m.Get("two")
+	assert.False(t, found)
+}
+
+func TestZeroValueMap(t *testing.T) {
+	var m Map[string, int]
+	_, found := m.Get("missing")
+	assert.False(t, found)
+	m.Delete("missing")
+	m.Clear()
+	assert.Zero(t, m.ProbeLength("missing"))
+	assert.Zero(t, m.Stats())
+	for range m.All() {
+		t.Fatal("empty map has entries")
+	}
+
+	for i := 0; i < 100; i++ {
+		m.Set(string(rune('a'+i)), i)
+	}
+
+	assert.Equal(t, 100, m.Len())
+	value, found := m.Get("b")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	m.Delete("b")
+	_, found = m.Get("b")
+	assert.False(t, found)
+	assert.Equal(t, 99, m.Len())
+}
+
+func TestMapMatchesBuiltin(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	m := New[int, int](0)
+	expected := make(map[int]int)
+
+	for i := 0; i < 200_000; i++ {
+		key := r.Intn(20_000)
+		switch r.Intn(3) {
+		case 0, 1:
+			m.Set(key, i)
+			expected[key] = i
+		case 2:
+			m.Delete(key)
+			delete(expected, key)
+		}
+	}
+
+	require.Equal(t, len(expected), m.Len())
+	for key, value := range expected {
+		actual, found := m.Get(key)
+		require.True(t, found)
+		require.Equal(t, value, actual)
+	}
+
+	count := 0
+	for key, value := range m.All() {
+		require.Equal(t, expected[key], value)
+		count++
+	}
+	require.Equal(t, len(expected), count)
+}
+
+func TestGrowth(t *testing.T) {
+	m := New[int, int](0)
+	assert.Equal(t, 1, m.Stats().Groups)
+
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+		assert.LessOrEqual(t, m.Stats().LoadFactor, 7.0/8.0)
+	}
+
+	stats := m.Stats()
+	assert.Equal(t, 256, stats.Groups)
+	assert.Equal(t, 1000, stats.Count)
+}
+
+func TestTombstonesAreReclaimed(t *testing.T) {
+	m := New[int, int](100)
+	groups := m.Stats().Groups
+
+	// churn with a stable number of entries must not grow the table
+	for i := 0; i < 100_000; i++ {
+		m.Set(i, i)
+		if i >= 50 {
+			m.Delete(i - 50)
+		}
+	}
+
+	assert.Equal(t, 50, m.Len())
+	assert.Equal(t, groups, m.Stats().Groups)
+	for i := 100_000 - 50; i < 100_000; i++ {
+		_, found := m.Get(i)
+		assert.True(t, found)
+	}
+}
+
+func TestProbeLength(t *testing.T) {
+	m := New[int, int](0)
+	for i := 0; i < 10_000; i++ {
+		m.Set(i, i)
+	}
+
+	total := 0
+	for i := 0; i < 10_000; i++ {
+		total += m.ProbeLength(i)
+	}
+
+	assert.Less(t, float64(total)/10_000, 2.0)
+}
@@ -0,0 +1,156 @@
+package builder
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Builder builds strings without copying the result like strings.Builder,
+// the zero value is ready to use, a non-zero Builder must not be copied
+type Builder struct {
+	addr   *Builder // to detect copies by value
+	buffer []byte
+	shared bool // bytes of the buffer are referenced by returned strings
+}
+
+func (b *Builder) copyCheck() {
+	if b.addr == nil {
+		b.addr = b
+	} else if b.addr != b {
+		panic("builder: illegal use of non-zero Builder copied by value")
+	}
+}
+
+// String returns the accumulated string without copying,
+// bytes are never modified afterwards
+func (b *Builder) String() string {
+	if len(b.buffer) == 0 {
+		return ""
+	}
+
+	b.shared = true
+	return unsafe.String(unsafe.SliceData(b.buffer), len(b.buffer))
+}
+
+// Bytes returns the accumulated bytes, they are valid until
+// the next modification and must not be changed
+func (b *Builder) Bytes() []byte {
+	return b.buffer
+}
+
+func (b *Builder) Len() int {
+	return len(b.buffer)
+}
+
+func (b *Builder) Cap() int {
+	return cap(b.buffer)
+}
+
+// Grow guarantees space for another n bytes without reallocation,
+// it never drops accumulated data
+func (b *Builder) Grow(n int) {
+	b.copyCheck()
+	if n < 0 {
+		panic("builder: negative Grow count")
+	}
+
+	if cap(b.buffer)-len(b.buffer) < n {
+		buffer := make([]byte, len(b.buffer), 2*cap(b.buffer)+n)
+		copy(buffer, b.buffer)
+		b.update(buffer)
+	}
+}
+
+// Reset drops accumulated data, the buffer can't be reused
+// because returned strings may point to it
+func (b *Builder) Reset() {
+	b.addr = nil
+	b.buffer = nil
+	b.shared = false
+}
+
+// Truncate keeps only the first n bytes
+func (b *Builder) Truncate(n int) {
+	b.copyCheck()
+	if n < 0 || n > len(b.buffer) {
+		panic(fmt.Sprintf("builder: truncation to %d out of range [0:%d]", n, len(b.buffer)))
+	}
+
+	if b.shared {
+		// capacity is cut so the next append reallocates instead
+		// of overwriting bytes of returned strings, the buffer stays
+		// shared until that happens
+		b.buffer = b.buffer[:n:n]
+		return
+	}
+
+	b.buffer = b.buffer[:n]
+}
+
+// update stores the result of an append, a new array isn't shared anymore
+func (b *Builder) update(buffer []byte) {
+	if unsafe.SliceData(buffer) != unsafe.SliceData(b.buffer) {
+		b.shared = false
+	}
+
+	b.buffer = buffer
+}
+
+func (b *Builder) Write(data []byte) (int, error) {
+	b.copyCheck()
+	b.update(append(b.buffer, data...))
+	return len(data), nil
+}
+
+func (b *Builder) WriteByte(symbol byte) error {
+	b.copyCheck()
+	b.update(append(b.buffer, symbol))
+	return nil
+}
+
+func (b *Builder) WriteString(text string) (int, error) {
+	b.copyCheck()
+	b.update(append(b.buffer, text...))
+	return len(text), nil
+}
+
+// WriteRune appends UTF-8 encoding of the rune,
+// invalid runes are written as utf8.RuneError
+func (b *Builder) WriteRune(symbol rune) (int, error) {
+	b.copyCheck()
+	length := len(b.buffer)
+	b.update(utf8.AppendRune(b.buffer, symbol))
+	return len(b.buffer) - length, nil
+}
+
+func (b *Builder) Printf(format string, args ...any) {
+	b.copyCheck()
+	b.update(fmt.Appendf(b.buffer, format, args...))
+}
+
+func (b *Builder) WriteInt(value int64, base int) {
+	b.copyCheck()
+	b.update(strconv.AppendInt(b.buffer, value, base))
+}
+
+func (b *Builder) WriteUint(value uint64, base int) {
+	b.copyCheck()
+	b.update(strconv.AppendUint(b.buffer, value, base))
+}
+
+func (b *Builder) WriteFloat(value float64, format byte, precision, bitSize int) {
+	b.copyCheck()
+	b.update(strconv.AppendFloat(b.buffer, value, format, precision, bitSize))
+}
+
+func (b *Builder) WriteBool(value bool) {
+	b.copyCheck()
+	b.update(strconv.AppendBool(b.buffer, value))
+}
+
+func (b *Builder) WriteQuoted(text string) {
+	b.copyCheck()
+	b.update(strconv.AppendQuote(b.buffer, text))
+}
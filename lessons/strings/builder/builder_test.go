@@ -0,0 +1,208 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v .
+
+var (
+	_ io.Writer       = (*Builder)(nil)
+	_ io.ByteWriter   = (*Builder)(nil)
+	_ io.StringWriter = (*Builder)(nil)
+	_ fmt.Stringer    = (*Builder)(nil)
+)
+
+func TestBuilderWrites(t *testing.T) {
+	var b Builder
+	b.WriteString("hello")
+	b.WriteByte(' ')
+	b.Write([]byte("мир"))
+	size, _ := b.WriteRune('!')
+	assert.Equal(t, 1, size)
+	size, _ = b.WriteRune('ы')
+	assert.Equal(t, 2, size)
+	size, _ = b.WriteRune(-1)
+	assert.Equal(t, 3, size)
+
+	assert.Equal(t, "hello мир!ы�", b.String())
+	assert.Equal(t, len("hello мир!ы�"), b.Len())
+}
+
+func TestFormattedAppends(t *testing.T) {
+	var b Builder
+	b.Printf("%s=%d ", "count", 42)
+	b.WriteInt(-255, 16)
+	b.WriteByte(' ')
+	b.WriteUint(7, 2)
+	b.WriteByte(' ')
+	b.WriteFloat(3.25, 'f', 1, 64)
+	b.WriteByte(' ')
+	b.WriteBool(true)
+	b.WriteByte(' ')
+	b.WriteQuoted("a\"b")
+
+	assert.Equal(t, `count=42 -ff 111 3.2 true "a\"b"`, b.String())
+}
+
+func TestGrowKeepsData(t *testing.T) {
+	var b Builder
+	b.WriteString("abc")
+	b.Grow(100)
+	assert.GreaterOrEqual(t, b.Cap(), 103)
+	assert.Equal(t, "abc", b.String())
+
+	capacity := b.Cap()
+	b.Grow(1)
+	assert.Equal(t, capacity, b.Cap())
+	assert.Panics(t, func() { b.Grow(-1) })
+}
+
+func TestStringIsNotModified(t *testing.T) {
+	var b Builder
+	b.Grow(64)
+	b.WriteString("hello world")
+	first := b.String()
+
+	b.Truncate(5)
+	b.WriteString(" there")
+	assert.Equal(t, "hello world", first)
+	assert.Equal(t, "hello there", b.String())
+
+	b.Reset()
+	assert.Equal(t, "", b.String())
+	b.WriteString("again")
+	assert.Equal(t, "hello world", first)
+	assert.Equal(t, "again", b.String())
+	assert.Panics(t, func() { b.Truncate(10) })
+}
+
+func TestStringSurvivesTruncations(t *testing.T) {
+	var b Builder
+	b.Grow(64)
+	b.WriteString("hello world")
+	first := b.String()
+
+	b.Truncate(5)
+	b.Truncate(2)
+	b.WriteString("XYZ")
+	assert.Equal(t, "hello world", first)
+	assert.Equal(t, "heXYZ", b.String())
+
+	second := b.String()
+	b.WriteString(" more")
+	b.Truncate(1)
+	b.WriteString("!")
+	b.Truncate(0)
+	b.WriteString("abcdefgh")
+	assert.Equal(t, "hello world", first)
+	assert.Equal(t, "heXYZ", second)
+	assert.Equal(t, "abcdefgh", b.String())
+}
+
+func TestTruncateAfterReallocation(t *testing.T) {
+	var b Builder
+	b.WriteString("shared")
+	shared := b.String()
+
+	// the write moves data into a new array, so it can be reused again
+	b.Truncate(3)
+	b.WriteString("red")
+	capacity := b.Cap()
+	b.Truncate(0)
+	b.WriteString("new")
+
+	assert.Equal(t, "shared", shared)
+	assert.Equal(t, "new", b.String())
+	assert.Equal(t, capacity, b.Cap())
+}
+
+func TestTruncateReusesBuffer(t *testing.T) {
+	var b Builder
+	b.WriteString("hello world")
+	capacity := b.Cap()
+
+	b.Truncate(0)
+	b.WriteString("bye")
+	assert.Equal(t, capacity, b.Cap())
+	assert.Equal(t, "bye", string(b.Bytes()))
+}
+
+func TestCopyCheck(t *testing.T) {
+	var b Builder
+	b.WriteString("data")
+	copied := b
+	assert.Panics(t, func() { copied.WriteString("more") })
+
+	// zero value can be copied
+	var empty Builder
+	copiedEmpty := empty
+	assert.NotPanics(t, func() { copiedEmpty.WriteString("more") })
+}
+
+func TestPool(t *testing.T) {
+	pool := NewPool(1024)
+
+	b := pool.Get()
+	b.WriteString("log line")
+	assert.Equal(t, "log line", string(b.Bytes()))
+	pool.Put(b)
+
+	b = pool.Get()
+	assert.Zero(t, b.Len())
+	b.WriteString("message")
+	message := b.String()
+	pool.Put(b)
+
+	// the buffer isn't reused once it was given out as a string
+	b = pool.Get()
+	b.WriteString("overwrite")
+	assert.Equal(t, "message", message)
+	pool.Put(b)
+
+	b = pool.Get()
+	b.Grow(4096)
+	pool.Put(b)
+}
+
+var Result string
+
+func BenchmarkBuilder(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var builder Builder
+		for j := 0; j < 100; j++ {
+			builder.WriteString("line ")
+			builder.WriteInt(int64(j), 10)
+		}
+		Result = builder.String()
+	}
+}
+
+func BenchmarkStringsBuilder(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var builder strings.Builder
+		for j := 0; j < 100; j++ {
+			builder.WriteString("line ")
+			builder.WriteString(fmt.Sprint(j))
+		}
+		Result = builder.String()
+	}
+}
+
+func BenchmarkPool(b *testing.B) {
+	pool := NewPool(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		builder := pool.Get()
+		builder.WriteString("request handled in ")
+		builder.WriteInt(int64(i), 10)
+		builder.WriteString("ms")
+		io.Discard.Write(builder.Bytes())
+		pool.Put(builder)
+	}
+}
@@ -0,0 +1,39 @@
+package builder
+
+import "sync"
+
+const defaultMaxCapacity = 64 << 10
+
+// Pool reuses builders on hot paths like logging,
+// buffers larger than the limit are not kept
+type Pool struct {
+	pool        sync.Pool
+	maxCapacity int
+}
+
+func NewPool(maxCapacity int) *Pool {
+	if maxCapacity <= 0 {
+		maxCapacity = defaultMaxCapacity
+	}
+
+	return &Pool{
+		pool:        sync.Pool{New: func() any { return new(Builder) }},
+		maxCapacity: maxCapacity,
+	}
+}
+
+func (p *Pool) Get() *Builder {
+	return p.pool.Get().(*Builder)
+}
+
+// Put returns the builder to the pool, it must not be used afterwards
+func (p *Pool) Put(b *Builder) {
+	if b.shared || cap(b.buffer) > p.maxCapacity {
+		// strings returned by the builder still point to the buffer
+		b.Reset()
+	} else {
+		b.buffer = b.buffer[:0]
+	}
+
+	p.pool.Put(b)
+}
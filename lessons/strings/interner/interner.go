@@ -0,0 +1,188 @@
+package interner
+
+import (
+	"hash/maphash"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+	"weak"
+)
+
+const defaultShards = 64
+
+// entry references bytes of the canonical string weakly,
+// so the string is collected when only the interner knows about it
+type entry struct {
+	data   weak.Pointer[byte]
+	length int
+}
+
+func (e entry) value() (string, bool) {
+	data := e.data.Value()
+	if data == nil {
+		return "", false
+	}
+
+	return unsafe.String(data, e.length), true
+}
+
+// shard keys entries by hash instead of by string,
+// a string key would keep the canonical instance alive
+type shard struct {
+	mutex   sync.Mutex
+	entries map[uint64][]entry
+}
+
+type cleanup struct {
+	shard *shard
+	hash  uint64
+	data  weak.Pointer[byte]
+}
+
+type Option func(*options)
+
+type options struct {
+	shards int
+}
+
+// WithShards sets the number of independently locked parts,
+// it is rounded up to a power of two
+func WithShards(shards int) Option {
+	return func(o *options) {
+		o.shards = shards
+	}
+}
+
+// Interner deduplicates strings into canonical instances,
+// unlike unique.Make it allows to observe what is going on
+type Interner struct {
+	seed   maphash.Seed
+	shards []shard
+	mask   uint64
+
+	hits       atomic.Int64
+	misses     atomic.Int64
+	bytesSaved atomic.Int64
+	live       atomic.Int64
+	collected  atomic.Int64
+}
+
+func New(opts ...Option) *Interner {
+	o := options{shards: defaultShards}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	count := 1
+	for count < o.shards {
+		count *= 2
+	}
+
+	interner := &Interner{
+		seed:   maphash.MakeSeed(),
+		shards: make([]shard, count),
+		mask:   uint64(count - 1),
+	}
+
+	for i := range interner.shards {
+		interner.shards[i].entries = make(map[uint64][]entry)
+	}
+
+	return interner
+}
+
+// Intern returns the canonical instance equal to the text
+func (i *Interner) Intern(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return i.intern(maphash.String(i.seed, text), text)
+}
+
+// InternBytes returns the canonical string equal to the data,
+// the data is copied only when it is seen for the first time
+func (i *Interner) InternBytes(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	// conversion for comparison doesn't allocate
+	text := unsafe.String(unsafe.SliceData(data), len(data))
+	return i.intern(maphash.Bytes(i.seed, data), text)
+}
+
+// heapCopy always allocates, conversions of short strings may
+// return static memory which can't be referenced weakly
+func heapCopy(text string) string {
+	data := make([]byte, len(text))
+	copy(data, text)
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
+
+func (i *Interner) intern(hash uint64, text string) string {
+	s := &i.shards[hash&i.mask]
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, e := range s.entries[hash] {
+		if canonical, ok := e.value(); ok && canonical == text {
+			i.hits.Add(1)
+			i.bytesSaved.Add(int64(len(text)))
+			return canonical
+		}
+	}
+
+	canonical := heapCopy(text)
+	data := unsafe.StringData(canonical)
+	e := entry{data: weak.Make(data), length: len(canonical)}
+	s.entries[hash] = append(s.entries[hash], e)
+	runtime.AddCleanup(data, i.remove, cleanup{shard: s, hash: hash, data: e.data})
+
+	i.misses.Add(1)
+	i.live.Add(1)
+	return canonical
+}
+
+func (i *Interner) remove(c cleanup) {
+	c.shard.mutex.Lock()
+	defer c.shard.mutex.Unlock()
+
+	entries := c.shard.entries[c.hash]
+	for index, e := range entries {
+		if e.data != c.data {
+			continue
+		}
+
+		entries[index] = entries[len(entries)-1]
+		entries = entries[:len(entries)-1]
+		if len(entries) == 0 {
+			delete(c.shard.entries, c.hash)
+		} else {
+			c.shard.entries[c.hash] = entries
+		}
+
+		i.live.Add(-1)
+		i.collected.Add(1)
+		return
+	}
+}
+
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	BytesSaved  int64 // bytes that would be held by duplicates
+	LiveEntries int64
+	Collected   int64
+}
+
+func (i *Interner) Stats() Stats {
+	return Stats{
+		Hits:        i.hits.Load(),
+		Misses:      i.misses.Load(),
+		BytesSaved:  i.bytesSaved.Load(),
+		LiveEntries: i.live.Load(),
+		Collected:   i.collected.Load(),
+	}
+}
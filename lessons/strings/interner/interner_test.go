@@ -0,0 +1,148 @@
+package interner
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func TestIntern(t *testing.T) {
+	interner := New()
+
+	first := interner.Intern(strings.Repeat("key", 10))
+	second := interner.Intern(strings.Repeat("key", 10))
+	third := interner.InternBytes([]byte(strings.Repeat("key", 10)))
+	other := interner.Intern("other key")
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, unsafe.StringData(first), unsafe.StringData(second))
+	assert.Equal(t, unsafe.StringData(first), unsafe.StringData(third))
+	assert.NotEqual(t, unsafe.StringData(first), unsafe.StringData(other))
+	assert.Equal(t, "", interner.Intern(""))
+	assert.Equal(t, "", interner.InternBytes(nil))
+
+	stats := interner.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(60), stats.BytesSaved)
+	assert.Equal(t, int64(2), stats.LiveEntries)
+
+	runtime.KeepAlive(first)
+	runtime.KeepAlive(other)
+}
+
+func TestShortInputs(t *testing.T) {
+	interner := New()
+	tests := map[string]string{
+		"empty":    "",
+		"one byte": "a",
+		"one rune": "я",
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			fromString := interner.Intern(text)
+			fromBytes := interner.InternBytes([]byte(text))
+			assert.Equal(t, text, fromString)
+			assert.Equal(t, text, fromBytes)
+			if text != "" {
+				assert.Equal(t, unsafe.StringData(fromString), unsafe.StringData(fromBytes))
+			}
+		})
+	}
+
+	// the first occurrence comes from bytes this time
+	assert.Equal(t, "b", interner.InternBytes([]byte{'b'}))
+	assert.Equal(t, "b", interner.Intern("b"))
+}
+
+func TestInternBytesCopies(t *testing.T) {
+	interner := New()
+	data := []byte("mutable bytes")
+	canonical := interner.InternBytes(data)
+	data[0] = 'M'
+
+	assert.Equal(t, "mutable bytes", canonical)
+}
+
+func TestUnusedEntriesAreCollected(t *testing.T) {
+	interner := New(WithShards(4))
+	for i := 0; i < 100; i++ {
+		interner.Intern(fmt.Sprintf("%064d", i))
+	}
+
+	kept := interner.Intern(strings.Repeat("k", 64))
+	require.Equal(t, int64(101), interner.Stats().LiveEntries)
+
+	// cleanups run in the background after garbage collection
+	deadline := time.Now().Add(5 * time.Second)
+	for interner.Stats().LiveEntries > 1 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stats := interner.Stats()
+	assert.Equal(t, int64(1), stats.LiveEntries)
+	assert.Equal(t, int64(100), stats.Collected)
+	assert.Equal(t, unsafe.StringData(kept), unsafe.StringData(interner.Intern(strings.Repeat("k", 64))))
+}
+
+func TestConcurrentIntern(t *testing.T) {
+	interner := New()
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("column_%d", i)
+	}
+
+	results := make([][]string, 8)
+	var wg sync.WaitGroup
+	for worker := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, key := range keys {
+				results[worker] = append(results[worker], interner.InternBytes([]byte(key)))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range results[1:] {
+		for i := range result {
+			assert.Equal(t, unsafe.StringData(results[0][i]), unsafe.StringData(result[i]))
+		}
+	}
+
+	stats := interner.Stats()
+	assert.Equal(t, int64(len(keys)), stats.Misses)
+	assert.Equal(t, int64(7*len(keys)), stats.Hits)
+}
+
+var Result string
+
+func BenchmarkInternBytes(b *testing.B) {
+	interner := New()
+	key := []byte("repeated_csv_column_name")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result = interner.InternBytes(key)
+	}
+}
+
+func BenchmarkStringConversion(b *testing.B) {
+	key := []byte("repeated_csv_column_name")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Result = string(key)
+	}
+}
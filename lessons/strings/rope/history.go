@@ -0,0 +1,40 @@
+package rope
+
+// History keeps versions of the text for undo and redo,
+// versions share most of their chunks
+type History struct {
+	versions []Rope
+	current  int
+}
+
+func NewHistory(initial Rope) *History {
+	return &History{versions: []Rope{initial}}
+}
+
+func (h *History) Current() Rope {
+	return h.versions[h.current]
+}
+
+// Push saves a new version and drops versions that could be redone
+func (h *History) Push(version Rope) {
+	h.versions = append(h.versions[:h.current+1], version)
+	h.current++
+}
+
+func (h *History) Undo() (Rope, bool) {
+	if h.current == 0 {
+		return h.Current(), false
+	}
+
+	h.current--
+	return h.Current(), true
+}
+
+func (h *History) Redo() (Rope, bool) {
+	if h.current == len(h.versions)-1 {
+		return h.Current(), false
+	}
+
+	h.current++
+	return h.Current(), true
+}
@@ -0,0 +1,138 @@
+package rope
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// leaves are merged while they are small and split when text is loaded
+const maxLeafSize = 1024
+
+// node is never modified after creation, so subtrees
+// are freely shared between ropes
+type node struct {
+	left  *node
+	right *node
+	text  string // only for leaves
+
+	length int // bytes
+	runes  int
+	lines  int // newlines
+	depth  int
+}
+
+func newLeaf(text string) *node {
+	if text == "" {
+		return nil
+	}
+
+	return &node{
+		text:   text,
+		length: len(text),
+		runes:  utf8.RuneCountInString(text),
+		lines:  strings.Count(text, "\n"),
+	}
+}
+
+func newNode(left, right *node) *node {
+	return &node{
+		left:   left,
+		right:  right,
+		length: left.length + right.length,
+		runes:  left.runes + right.runes,
+		lines:  left.lines + right.lines,
+		depth:  max(left.depth, right.depth) + 1,
+	}
+}
+
+func (n *node) isLeaf() bool {
+	return n.left == nil
+}
+
+func depth(n *node) int {
+	if n == nil {
+		return -1
+	}
+
+	return n.depth
+}
+
+// build creates a balanced tree with leaves cut on rune boundaries
+func build(text string) *node {
+	if len(text) <= maxLeafSize {
+		return newLeaf(text)
+	}
+
+	middle := len(text) / 2
+	for middle > 0 && !utf8.RuneStart(text[middle]) {
+		middle--
+	}
+
+	return newNode(build(text[:middle]), build(text[middle:]))
+}
+
+// join concatenates trees keeping them AVL-balanced,
+// only nodes on the path to the joint are recreated
+func join(left, right *node) *node {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.isLeaf() && right.isLeaf() && left.length+right.length <= maxLeafSize:
+		return newLeaf(left.text + right.text)
+	case left.depth > right.depth+1:
+		return balance(newNode(left.left, join(left.right, right)))
+	case right.depth > left.depth+1:
+		return balance(newNode(join(left, right.left), right.right))
+	default:
+		return newNode(left, right)
+	}
+}
+
+func balance(n *node) *node {
+	switch {
+	case n.left.depth > n.right.depth+1:
+		left := n.left
+		if depth(left.right) > depth(left.left) {
+			left = rotateLeft(left)
+		}
+		return rotateRight(newNode(left, n.right))
+	case n.right.depth > n.left.depth+1:
+		right := n.right
+		if depth(right.left) > depth(right.right) {
+			right = rotateRight(right)
+		}
+		return rotateLeft(newNode(n.left, right))
+	default:
+		return n
+	}
+}
+
+func rotateLeft(n *node) *node {
+	return newNode(newNode(n.left, n.right.left), n.right.right)
+}
+
+func rotateRight(n *node) *node {
+	return newNode(n.left.left, newNode(n.left.right, n.right))
+}
+
+// split divides the tree at the byte offset
+func split(n *node, offset int) (*node, *node) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case offset <= 0:
+		return nil, n
+	case offset >= n.length:
+		return n, nil
+	case n.isLeaf():
+		return newLeaf(n.text[:offset]), newLeaf(n.text[offset:])
+	case offset < n.left.length:
+		left, right := split(n.left, offset)
+		return left, join(right, n.right)
+	default:
+		left, right := split(n.right, offset-n.left.length)
+		return join(n.left, left), right
+	}
+}
@@ -0,0 +1,297 @@
+package rope
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rope is an immutable text, every edit returns a new rope
+// sharing unchanged chunks with the original one, so keeping
+// old versions for undo costs O(log n) per edit
+//
+// offsets are in bytes like for strings, edits inside
+// of a multibyte rune make rune counts meaningless
+type Rope struct {
+	root *node
+}
+
+func New(text string) Rope {
+	return Rope{root: build(text)}
+}
+
+// Len returns the number of bytes
+func (r Rope) Len() int {
+	if r.root == nil {
+		return 0
+	}
+
+	return r.root.length
+}
+
+func (r Rope) RuneCount() int {
+	if r.root == nil {
+		return 0
+	}
+
+	return r.root.runes
+}
+
+// LineCount returns the number of lines, text without newlines is one line
+func (r Rope) LineCount() int {
+	if r.root == nil {
+		return 1
+	}
+
+	return r.root.lines + 1
+}
+
+// Depth returns the height of the tree
+func (r Rope) Depth() int {
+	return depth(r.root) + 1
+}
+
+func (r Rope) checkOffset(offset int) {
+	if offset < 0 || offset > r.Len() {
+		panic(fmt.Sprintf("rope: offset %d out of range [0:%d]", offset, r.Len()))
+	}
+}
+
+func (r Rope) checkRange(from, to int) {
+	if from < 0 || to > r.Len() || from > to {
+		panic(fmt.Sprintf("rope: range [%d:%d] out of range [0:%d]", from, to, r.Len()))
+	}
+}
+
+func (r Rope) Insert(offset int, text string) Rope {
+	r.checkOffset(offset)
+	left, right := split(r.root, offset)
+	return Rope{root: join(join(left, build(text)), right)}
+}
+
+// Delete removes bytes in [from:to)
+func (r Rope) Delete(from, to int) Rope {
+	r.checkRange(from, to)
+	left, rest := split(r.root, from)
+	_, right := split(rest, to-from)
+	return Rope{root: join(left, right)}
+}
+
+// Slice returns bytes in [from:to) as a rope sharing chunks with the original
+func (r Rope) Slice(from, to int) Rope {
+	r.checkRange(from, to)
+	_, rest := split(r.root, from)
+	middle, _ := split(rest, to-from)
+	return Rope{root: middle}
+}
+
+func (r Rope) Concat(other Rope) Rope {
+	return Rope{root: join(r.root, other.root)}
+}
+
+func (r Rope) ByteAt(offset int) byte {
+	if offset < 0 || offset >= r.Len() {
+		panic(fmt.Sprintf("rope: index %d out of range [0:%d]", offset, r.Len()))
+	}
+
+	n := r.root
+	for !n.isLeaf() {
+		if offset < n.left.length {
+			n = n.left
+		} else {
+			offset -= n.left.length
+			n = n.right
+		}
+	}
+
+	return n.text[offset]
+}
+
+// RuneAt returns the rune with the index counted in runes
+func (r Rope) RuneAt(index int) rune {
+	if index < 0 || index >= r.RuneCount() {
+		panic(fmt.Sprintf("rope: rune index %d out of range [0:%d]", index, r.RuneCount()))
+	}
+
+	offset := r.ByteOffset(index)
+	var buffer [utf8.UTFMax]byte
+	size := 0
+	for ; size < len(buffer) && offset+size < r.Len(); size++ {
+		buffer[size] = r.ByteAt(offset + size)
+	}
+
+	value, _ := utf8.DecodeRune(buffer[:size])
+	return value
+}
+
+// ByteOffset converts the rune index into the byte offset
+func (r Rope) ByteOffset(runeIndex int) int {
+	if runeIndex < 0 || runeIndex > r.RuneCount() {
+		panic(fmt.Sprintf("rope: rune index %d out of range [0:%d]", runeIndex, r.RuneCount()))
+	}
+
+	offset := 0
+	n := r.root
+	for n != nil && !n.isLeaf() {
+		if runeIndex < n.left.runes {
+			n = n.left
+		} else {
+			runeIndex -= n.left.runes
+			offset += n.left.length
+			n = n.right
+		}
+	}
+
+	if n != nil {
+		for position := range n.text {
+			if runeIndex == 0 {
+				return offset + position
+			}
+			runeIndex--
+		}
+		offset += n.length
+	}
+
+	return offset
+}
+
+// RuneOffset converts the byte offset into the rune index
+func (r Rope) RuneOffset(offset int) int {
+	r.checkOffset(offset)
+
+	runes := 0
+	n := r.root
+	for n != nil && !n.isLeaf() {
+		if offset < n.left.length {
+			n = n.left
+		} else {
+			offset -= n.left.length
+			runes += n.left.runes
+			n = n.right
+		}
+	}
+
+	if n != nil {
+		runes += utf8.RuneCountInString(n.text[:offset])
+	}
+
+	return runes
+}
+
+// LineStart returns the byte offset of the line, lines are counted from zero
+func (r Rope) LineStart(line int) int {
+	if line < 0 || line >= r.LineCount() {
+		panic(fmt.Sprintf("rope: line %d out of range [0:%d]", line, r.LineCount()))
+	}
+
+	if line == 0 {
+		return 0
+	}
+
+	// looking for the end of the previous line
+	offset := 0
+	n := r.root
+	for !n.isLeaf() {
+		if line <= n.left.lines {
+			n = n.left
+		} else {
+			line -= n.left.lines
+			offset += n.left.length
+			n = n.right
+		}
+	}
+
+	for position := 0; ; position++ {
+		if n.text[position] == '\n' {
+			if line--; line == 0 {
+				return offset + position + 1
+			}
+		}
+	}
+}
+
+// LineColumn returns the zero-based line and the column in runes of the byte offset
+func (r Rope) LineColumn(offset int) (int, int) {
+	r.checkOffset(offset)
+
+	line := 0
+	remaining := offset
+	n := r.root
+	for n != nil && !n.isLeaf() {
+		if remaining < n.left.length {
+			n = n.left
+		} else {
+			remaining -= n.left.length
+			line += n.left.lines
+			n = n.right
+		}
+	}
+
+	if n != nil {
+		line += strings.Count(n.text[:remaining], "\n")
+	}
+
+	column := r.RuneOffset(offset) - r.RuneOffset(r.LineStart(line))
+	return line, column
+}
+
+// Offset converts the zero-based line and the column in runes into the byte offset
+func (r Rope) Offset(line, column int) int {
+	start := r.LineStart(line)
+	return r.ByteOffset(r.RuneOffset(start) + column)
+}
+
+// Line returns the text of the line without the trailing newline
+func (r Rope) Line(line int) string {
+	start := r.LineStart(line)
+	end := r.Len()
+	if line+1 < r.LineCount() {
+		end = r.LineStart(line+1) - 1
+	}
+
+	return r.Slice(start, end).String()
+}
+
+// Chunks iterates over leaves without copying
+func (r Rope) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		walk(r.root, yield)
+	}
+}
+
+func walk(n *node, yield func(string) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.isLeaf() {
+		return yield(n.text)
+	}
+
+	return walk(n.left, yield) && walk(n.right, yield)
+}
+
+func (r Rope) String() string {
+	var builder strings.Builder
+	builder.Grow(r.Len())
+	for chunk := range r.Chunks() {
+		builder.WriteString(chunk)
+	}
+
+	return builder.String()
+}
+
+func (r Rope) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for chunk := range r.Chunks() {
+		n, err := io.WriteString(w, chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,215 @@
+package rope
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func TestRopeEditing(t *testing.T) {
+	r := New("Hello world")
+	assert.Equal(t, "Hello, world", r.Insert(5, ",").String())
+	assert.Equal(t, "Hello", r.Delete(5, 11).String())
+	assert.Equal(t, "lo wo", r.Slice(3, 8).String())
+	assert.Equal(t, "Hello world!!!", r.Concat(New("!!!")).String())
+	assert.Equal(t, "Hello world", r.String())
+
+	assert.Equal(t, "", New("").String())
+	assert.Equal(t, 0, New("").Len())
+	assert.Equal(t, "abc", New("").Insert(0, "abc").String())
+	assert.Panics(t, func() { r.Insert(12, "x") })
+	assert.Panics(t, func() { r.Delete(5, 3) })
+}
+
+func TestRopeMatchesString(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	alphabet := []rune("abc\nабв日本")
+	randomText := func(size int) string {
+		runes := make([]rune, size)
+		for i := range runes {
+			runes[i] = alphabet[random.Intn(len(alphabet))]
+		}
+		return string(runes)
+	}
+
+	// offsets are taken on rune boundaries
+	randomOffset := func(text string) int {
+		runes := utf8.RuneCountInString(text)
+		index := random.Intn(runes + 1)
+		return len(string([]rune(text)[:index]))
+	}
+
+	expected := randomText(5000)
+	r := New(expected)
+	for i := 0; i < 2000; i++ {
+		switch random.Intn(3) {
+		case 0:
+			offset, text := randomOffset(expected), randomText(random.Intn(300))
+			expected = expected[:offset] + text + expected[offset:]
+			r = r.Insert(offset, text)
+		case 1:
+			from, to := randomOffset(expected), randomOffset(expected)
+			from, to = min(from, to), max(from, to)
+			expected = expected[:from] + expected[to:]
+			r = r.Delete(from, to)
+		case 2:
+			from, to := randomOffset(expected), randomOffset(expected)
+			from, to = min(from, to), max(from, to)
+			require.Equal(t, expected[from:to], r.Slice(from, to).String())
+		}
+
+		require.Equal(t, len(expected), r.Len())
+		require.Equal(t, utf8.RuneCountInString(expected), r.RuneCount())
+	}
+
+	require.Equal(t, expected, r.String())
+	require.Equal(t, strings.Count(expected, "\n")+1, r.LineCount())
+	assertBalanced(t, r.root)
+}
+
+func assertBalanced(t *testing.T, n *node) {
+	if n == nil || n.isLeaf() {
+		return
+	}
+
+	require.LessOrEqual(t, abs(n.left.depth-n.right.depth), 1)
+	assertBalanced(t, n.left)
+	assertBalanced(t, n.right)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func TestIndexing(t *testing.T) {
+	text := strings.Repeat("привет, мир\nhello\n", 200)
+	r := New(text)
+	runes := []rune(text)
+
+	for _, index := range []int{0, 1, 7, 100, 1000, len(runes) - 1} {
+		assert.Equal(t, runes[index], r.RuneAt(index))
+		offset := r.ByteOffset(index)
+		assert.Equal(t, len(string(runes[:index])), offset)
+		assert.Equal(t, index, r.RuneOffset(offset))
+	}
+
+	assert.Equal(t, len(text), r.ByteOffset(len(runes)))
+	assert.Equal(t, text[1500], r.ByteAt(1500))
+	assert.Panics(t, func() { r.ByteAt(len(text)) })
+}
+
+func TestLines(t *testing.T) {
+	text := strings.Repeat("первая строка\nsecond\n\n", 100) + "last"
+	r := New(text)
+	lines := strings.Split(text, "\n")
+	require.Equal(t, len(lines), r.LineCount())
+
+	offset := 0
+	for index, line := range lines {
+		assert.Equal(t, offset, r.LineStart(index))
+		assert.Equal(t, line, r.Line(index))
+
+		l, column := r.LineColumn(offset + len(line))
+		assert.Equal(t, index, l)
+		assert.Equal(t, utf8.RuneCountInString(line), column)
+		assert.Equal(t, offset+len(line), r.Offset(index, column))
+
+		offset += len(line) + 1
+	}
+}
+
+func TestSnapshotsShareChunks(t *testing.T) {
+	original := New(strings.Repeat("x", 1<<20))
+	edited := original.Insert(1<<19, "y")
+
+	chunks := make(map[*node]struct{})
+	collect(original.root, chunks)
+
+	shared, total := 0, 0
+	visit(edited.root, func(n *node) {
+		total++
+		if _, found := chunks[n]; found {
+			shared++
+		}
+	})
+
+	assert.Equal(t, strings.Repeat("x", 1<<20), original.String())
+	assert.Greater(t, shared, total*9/10)
+}
+
+func collect(n *node, nodes map[*node]struct{}) {
+	visit(n, func(n *node) { nodes[n] = struct{}{} })
+}
+
+func visit(n *node, fn func(*node)) {
+	if n == nil {
+		return
+	}
+	if n.isLeaf() {
+		fn(n)
+		return
+	}
+	visit(n.left, fn)
+	visit(n.right, fn)
+}
+
+func TestHistory(t *testing.T) {
+	history := NewHistory(New("a"))
+	history.Push(history.Current().Insert(1, "b"))
+	history.Push(history.Current().Insert(2, "c"))
+
+	r, ok := history.Undo()
+	assert.True(t, ok)
+	assert.Equal(t, "ab", r.String())
+
+	r, ok = history.Redo()
+	assert.True(t, ok)
+	assert.Equal(t, "abc", r.String())
+
+	history.Undo()
+	history.Undo()
+	_, ok = history.Undo()
+	assert.False(t, ok)
+
+	history.Push(history.Current().Insert(0, "z"))
+	_, ok = history.Redo()
+	assert.False(t, ok)
+	assert.Equal(t, "za", history.Current().String())
+}
+
+func TestWriteTo(t *testing.T) {
+	text := strings.Repeat("abc", 5000)
+	var buffer bytes.Buffer
+	written, err := New(text).WriteTo(&buffer)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(text)), written)
+	assert.Equal(t, text, buffer.String())
+}
+
+var Result int
+
+func BenchmarkRopeInsert(b *testing.B) {
+	r := New(strings.Repeat("x", 4<<20))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result = r.Insert(2<<20, "y").Len()
+	}
+}
+
+func BenchmarkStringInsert(b *testing.B) {
+	text := strings.Repeat("x", 4<<20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Result = len(text[:2<<20] + "y" + text[2<<20:])
+	}
+}
@@ -15,9 +15,8 @@ func (b *Builder) Grow(capacity int) {
 		return
 	}
 
-	if capacity < len(b.buffer) {
-		b.buffer = b.buffer[:capacity]
-		return
+	if capacity <= cap(b.buffer) {
+		return // never drop written data
 	}
 
 	buffer := make([]byte, len(b.buffer), capacity)
@@ -0,0 +1,339 @@
+package textstats
+
+import "unicode"
+
+const (
+	zeroWidthNonJoiner = '\u200c'
+	zeroWidthJoiner    = '\u200d'
+)
+
+// graphemeClass is the Grapheme_Cluster_Break property of UAX #29,
+// the unicode package has no table for it, so it is derived
+// from general categories and a few ranges below
+type graphemeClass uint8
+
+const (
+	classOther graphemeClass = iota
+	classCR
+	classLF
+	classControl
+	classExtend
+	classZWJ
+	classRegionalIndicator
+	classPrepend
+	classSpacingMark
+	classL
+	classV
+	classT
+	classLV
+	classLVT
+)
+
+var emojiModifier = &unicode.RangeTable{
+	R32: []unicode.Range32{{Lo: 0x1F3FB, Hi: 0x1F3FF, Stride: 1}},
+}
+
+// prepend holds letters which are not prepended concatenation marks
+var prepend = &unicode.RangeTable{
+	R16: []unicode.Range16{{Lo: 0x0D4E, Hi: 0x0D4E, Stride: 1}},
+	R32: []unicode.Range32{
+		{Lo: 0x111C2, Hi: 0x111C3, Stride: 1},
+		{Lo: 0x1193F, Hi: 0x1193F, Stride: 1},
+		{Lo: 0x11941, Hi: 0x11941, Stride: 1},
+		{Lo: 0x11A3A, Hi: 0x11A3A, Stride: 1},
+		{Lo: 0x11A84, Hi: 0x11A89, Stride: 1},
+		{Lo: 0x11D46, Hi: 0x11D46, Stride: 1},
+		{Lo: 0x11F02, Hi: 0x11F02, Stride: 1},
+	},
+}
+
+// spacingMark adds vowels of Thai and Lao, which are letters
+var spacingMark = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0E33, Hi: 0x0E33, Stride: 1},
+		{Lo: 0x0EB3, Hi: 0x0EB3, Stride: 1},
+	},
+}
+
+// notSpacingMark holds spacing combining marks which still break clusters
+var notSpacingMark = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x102B, Hi: 0x102C, Stride: 1},
+		{Lo: 0x1038, Hi: 0x1038, Stride: 1},
+		{Lo: 0x1062, Hi: 0x1064, Stride: 1},
+		{Lo: 0x1067, Hi: 0x106D, Stride: 1},
+		{Lo: 0x1083, Hi: 0x1083, Stride: 1},
+		{Lo: 0x1087, Hi: 0x108C, Stride: 1},
+		{Lo: 0x108F, Hi: 0x108F, Stride: 1},
+		{Lo: 0x109A, Hi: 0x109C, Stride: 1},
+		{Lo: 0x1A61, Hi: 0x1A61, Stride: 1},
+		{Lo: 0x1A63, Hi: 0x1A64, Stride: 1},
+		{Lo: 0xAA7B, Hi: 0xAA7B, Stride: 1},
+		{Lo: 0xAA7D, Hi: 0xAA7D, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x11720, Hi: 0x11721, Stride: 1},
+	},
+}
+
+// extendedPictographic joins emoji sequences with ZWJ
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00A9, Hi: 0x00A9, Stride: 1},
+		{Lo: 0x00AE, Hi: 0x00AE, Stride: 1},
+		{Lo: 0x203C, Hi: 0x203C, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21A9, Hi: 0x21AA, Stride: 1},
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x2388, Hi: 0x2388, Stride: 1},
+		{Lo: 0x23CF, Hi: 0x23CF, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23F3, Stride: 1},
+		{Lo: 0x23F8, Hi: 0x23FA, Stride: 1},
+		{Lo: 0x24C2, Hi: 0x24C2, Stride: 1},
+		{Lo: 0x25AA, Hi: 0x25AB, Stride: 1},
+		{Lo: 0x25B6, Hi: 0x25B6, Stride: 1},
+		{Lo: 0x25C0, Hi: 0x25C0, Stride: 1},
+		{Lo: 0x25FB, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2600, Hi: 0x2605, Stride: 1},
+		{Lo: 0x2607, Hi: 0x2612, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2685, Stride: 1},
+		{Lo: 0x2690, Hi: 0x2705, Stride: 1},
+		{Lo: 0x2708, Hi: 0x2712, Stride: 1},
+		{Lo: 0x2714, Hi: 0x2714, Stride: 1},
+		{Lo: 0x2716, Hi: 0x2716, Stride: 1},
+		{Lo: 0x271D, Hi: 0x271D, Stride: 1},
+		{Lo: 0x2721, Hi: 0x2721, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x2733, Hi: 0x2734, Stride: 1},
+		{Lo: 0x2744, Hi: 0x2744, Stride: 1},
+		{Lo: 0x2747, Hi: 0x2747, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274C, Stride: 1},
+		{Lo: 0x274E, Hi: 0x274E, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2763, Hi: 0x2767, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27A1, Hi: 0x27A1, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27B0, Stride: 1},
+		{Lo: 0x27BF, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2B05, Hi: 0x2B07, Stride: 1},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303D, Hi: 0x303D, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F000, Hi: 0x1F0FF, Stride: 1},
+		{Lo: 0x1F10D, Hi: 0x1F10F, Stride: 1},
+		{Lo: 0x1F12F, Hi: 0x1F12F, Stride: 1},
+		{Lo: 0x1F16C, Hi: 0x1F171, Stride: 1},
+		{Lo: 0x1F17E, Hi: 0x1F17F, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F1AD, Hi: 0x1F1E5, Stride: 1},
+		{Lo: 0x1F201, Hi: 0x1F20F, Stride: 1},
+		{Lo: 0x1F21A, Hi: 0x1F21A, Stride: 1},
+		{Lo: 0x1F22F, Hi: 0x1F22F, Stride: 1},
+		{Lo: 0x1F232, Hi: 0x1F23A, Stride: 1},
+		{Lo: 0x1F23C, Hi: 0x1F23F, Stride: 1},
+		{Lo: 0x1F249, Hi: 0x1F3FA, Stride: 1},
+		{Lo: 0x1F400, Hi: 0x1F53D, Stride: 1},
+		{Lo: 0x1F546, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F680, Hi: 0x1F6FF, Stride: 1},
+		{Lo: 0x1F774, Hi: 0x1F77F, Stride: 1},
+		{Lo: 0x1F7D5, Hi: 0x1F7FF, Stride: 1},
+		{Lo: 0x1F80C, Hi: 0x1F80F, Stride: 1},
+		{Lo: 0x1F848, Hi: 0x1F84F, Stride: 1},
+		{Lo: 0x1F85A, Hi: 0x1F85F, Stride: 1},
+		{Lo: 0x1F888, Hi: 0x1F88F, Stride: 1},
+		{Lo: 0x1F8AE, Hi: 0x1F8FF, Stride: 1},
+		{Lo: 0x1F90C, Hi: 0x1F93A, Stride: 1},
+		{Lo: 0x1F93C, Hi: 0x1F945, Stride: 1},
+		{Lo: 0x1F947, Hi: 0x1FAFF, Stride: 1},
+		{Lo: 0x1FC00, Hi: 0x1FFFD, Stride: 1},
+	},
+}
+
+// conjunctConsonant and conjunctLinker form Indic conjuncts (GB9c),
+// the linker is the virama of Devanagari, Bengali, Gujarati,
+// Oriya, Telugu and Malayalam
+var conjunctConsonant = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0915, Hi: 0x0939, Stride: 1},
+		{Lo: 0x0958, Hi: 0x095F, Stride: 1},
+		{Lo: 0x0978, Hi: 0x097F, Stride: 1},
+		{Lo: 0x0995, Hi: 0x09A8, Stride: 1},
+		{Lo: 0x09AA, Hi: 0x09B0, Stride: 1},
+		{Lo: 0x09B2, Hi: 0x09B2, Stride: 1},
+		{Lo: 0x09B6, Hi: 0x09B9, Stride: 1},
+		{Lo: 0x09DC, Hi: 0x09DD, Stride: 1},
+		{Lo: 0x09DF, Hi: 0x09DF, Stride: 1},
+		{Lo: 0x09F0, Hi: 0x09F1, Stride: 1},
+		{Lo: 0x0A95, Hi: 0x0AA8, Stride: 1},
+		{Lo: 0x0AAA, Hi: 0x0AB0, Stride: 1},
+		{Lo: 0x0AB2, Hi: 0x0AB3, Stride: 1},
+		{Lo: 0x0AB5, Hi: 0x0AB9, Stride: 1},
+		{Lo: 0x0AF9, Hi: 0x0AF9, Stride: 1},
+		{Lo: 0x0B15, Hi: 0x0B28, Stride: 1},
+		{Lo: 0x0B2A, Hi: 0x0B30, Stride: 1},
+		{Lo: 0x0B32, Hi: 0x0B33, Stride: 1},
+		{Lo: 0x0B35, Hi: 0x0B39, Stride: 1},
+		{Lo: 0x0B5C, Hi: 0x0B5D, Stride: 1},
+		{Lo: 0x0B5F, Hi: 0x0B5F, Stride: 1},
+		{Lo: 0x0B71, Hi: 0x0B71, Stride: 1},
+		{Lo: 0x0C15, Hi: 0x0C28, Stride: 1},
+		{Lo: 0x0C2A, Hi: 0x0C39, Stride: 1},
+		{Lo: 0x0C58, Hi: 0x0C5A, Stride: 1},
+		{Lo: 0x0D15, Hi: 0x0D3A, Stride: 1},
+	},
+}
+
+var conjunctLinker = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x094D, Hi: 0x094D, Stride: 1},
+		{Lo: 0x09CD, Hi: 0x09CD, Stride: 1},
+		{Lo: 0x0ACD, Hi: 0x0ACD, Stride: 1},
+		{Lo: 0x0B4D, Hi: 0x0B4D, Stride: 1},
+		{Lo: 0x0C4D, Hi: 0x0C4D, Stride: 1},
+		{Lo: 0x0D4D, Hi: 0x0D4D, Stride: 1},
+	},
+}
+
+const (
+	hangulBase  = 0xAC00
+	hangulLast  = 0xD7A3
+	hangulTails = 28
+)
+
+func classify(symbol rune) graphemeClass {
+	switch {
+	case symbol == '\r':
+		return classCR
+	case symbol == '\n':
+		return classLF
+	case symbol == zeroWidthJoiner:
+		return classZWJ
+	case unicode.Is(unicode.Regional_Indicator, symbol):
+		return classRegionalIndicator
+	case symbol == zeroWidthNonJoiner,
+		unicode.In(symbol, unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend, emojiModifier):
+		return classExtend
+	case unicode.In(symbol, unicode.Prepended_Concatenation_Mark, prepend):
+		return classPrepend
+	case unicode.In(symbol, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return classControl
+	case symbol >= 0x1100 && symbol <= 0x115F, symbol >= 0xA960 && symbol <= 0xA97C:
+		return classL
+	case symbol >= 0x1160 && symbol <= 0x11A7, symbol >= 0xD7B0 && symbol <= 0xD7C6:
+		return classV
+	case symbol >= 0x11A8 && symbol <= 0x11FF, symbol >= 0xD7CB && symbol <= 0xD7FB:
+		return classT
+	case symbol >= hangulBase && symbol <= hangulLast:
+		if (symbol-hangulBase)%hangulTails == 0 {
+			return classLV
+		}
+		return classLVT
+	case unicode.Is(unicode.Mc, symbol) && !unicode.Is(notSpacingMark, symbol),
+		unicode.Is(spacingMark, symbol):
+		return classSpacingMark
+	default:
+		return classOther
+	}
+}
+
+const (
+	conjunctNone    = iota
+	conjunctStarted // a consonant followed by extending marks
+	conjunctLinked  // the linker after the consonant joins the next one
+)
+
+// graphemeSegmenter finds boundaries of extended grapheme clusters
+// by UAX #29 rules, runes are fed one by one
+type graphemeSegmenter struct {
+	started  bool
+	previous graphemeClass
+
+	regional     int  // regional indicators in a row, pairs make flags
+	pictographic bool // emoji followed by extending marks
+	emojiJoiner  bool // ZWJ after such emoji joins the next one
+	conjunct     int
+}
+
+// breaks reports whether the symbol starts a new cluster
+func (s *graphemeSegmenter) breaks(symbol rune) bool {
+	class := classify(symbol)
+	result := s.boundary(symbol, class)
+	s.update(symbol, class)
+	return result
+}
+
+func (s *graphemeSegmenter) boundary(symbol rune, class graphemeClass) bool {
+	previous := s.previous
+	switch {
+	case !s.started:
+		return true
+	case previous == classCR && class == classLF: // GB3
+		return false
+	case previous == classCR || previous == classLF || previous == classControl: // GB4
+		return true
+	case class == classCR || class == classLF || class == classControl: // GB5
+		return true
+	case previous == classL && (class == classL || class == classV || class == classLV || class == classLVT): // GB6
+		return false
+	case (previous == classLV || previous == classV) && (class == classV || class == classT): // GB7
+		return false
+	case (previous == classLVT || previous == classT) && class == classT: // GB8
+		return false
+	case class == classExtend || class == classZWJ || class == classSpacingMark: // GB9, GB9a
+		return false
+	case previous == classPrepend: // GB9b
+		return false
+	case s.conjunct == conjunctLinked && unicode.Is(conjunctConsonant, symbol): // GB9c
+		return false
+	case s.emojiJoiner && unicode.Is(extendedPictographic, symbol): // GB11
+		return false
+	case class == classRegionalIndicator && s.regional%2 == 1: // GB12, GB13
+		return false
+	default: // GB999
+		return true
+	}
+}
+
+func (s *graphemeSegmenter) update(symbol rune, class graphemeClass) {
+	s.started = true
+	s.previous = class
+
+	if class == classRegionalIndicator {
+		s.regional++
+	} else {
+		s.regional = 0
+	}
+
+	switch {
+	case unicode.Is(extendedPictographic, symbol):
+		s.pictographic, s.emojiJoiner = true, false
+	case class == classExtend && s.pictographic:
+	case class == classZWJ && s.pictographic:
+		s.pictographic, s.emojiJoiner = false, true
+	default:
+		s.pictographic, s.emojiJoiner = false, false
+	}
+
+	switch {
+	case unicode.Is(conjunctConsonant, symbol):
+		s.conjunct = conjunctStarted
+	case s.conjunct != conjunctNone && unicode.Is(conjunctLinker, symbol):
+		s.conjunct = conjunctLinked
+	case s.conjunct != conjunctNone && (class == classExtend || class == classZWJ):
+	default:
+		s.conjunct = conjunctNone
+	}
+}
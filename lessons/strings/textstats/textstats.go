@@ -0,0 +1,228 @@
+package textstats
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const defaultTopN = 10
+
+type Option func(*options)
+
+type options struct {
+	normalize bool
+	form      norm.Form
+	fold      bool
+	topN      int
+}
+
+// WithNormalization converts text into the form before counting,
+// so "й" typed as "и" + combining breve is the same letter as precomposed "й"
+func WithNormalization(form norm.Form) Option {
+	return func(o *options) {
+		o.normalize = true
+		o.form = form
+	}
+}
+
+// WithCaseFolding makes counting case-insensitive
+func WithCaseFolding() Option {
+	return func(o *options) {
+		o.fold = true
+	}
+}
+
+// WithTopN limits frequency tables, zero or negative means no limit
+func WithTopN(n int) Option {
+	return func(o *options) {
+		o.topN = n
+	}
+}
+
+type Frequency struct {
+	Value string
+	Count int
+}
+
+type Report struct {
+	Bytes     int // of the input before normalization
+	Runes     int
+	Graphemes int // extended grapheme clusters of UAX #29
+	Words     int
+	Lines     int
+
+	Letters     []Frequency
+	TopWords    []Frequency
+	UniqueWords int
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int
+}
+
+func (r *countingReader) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	r.count += n
+	return n, err
+}
+
+// Analyze reads the text once, memory depends only on
+// the number of distinct words and letters
+func Analyze(reader io.Reader, opts ...Option) (Report, error) {
+	o := options{topN: defaultTopN}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	input := &countingReader{reader: reader}
+	var source io.Reader = input
+	if o.fold {
+		source = transform.NewReader(source, cases.Fold())
+	}
+	if o.normalize {
+		// normalization goes after folding, which can decompose runes
+		source = o.form.Reader(source)
+	}
+
+	c := newCounter()
+	buffered := bufio.NewReader(source)
+	for {
+		symbol, _, err := buffered.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return Report{}, err
+		}
+
+		c.add(symbol)
+	}
+	c.finish()
+
+	return Report{
+		Bytes:       input.count,
+		Runes:       c.runes,
+		Graphemes:   c.graphemes,
+		Words:       c.words,
+		Lines:       c.lines,
+		Letters:     top(c.letters, o.topN, func(letter rune) string { return string(letter) }),
+		TopWords:    top(c.wordCounts, o.topN, func(word string) string { return word }),
+		UniqueWords: len(c.wordCounts),
+	}, nil
+}
+
+func AnalyzeString(text string, opts ...Option) Report {
+	report, _ := Analyze(strings.NewReader(text), opts...)
+	return report
+}
+
+func top[K comparable](counts map[K]int, n int, format func(K) string) []Frequency {
+	frequencies := make([]Frequency, 0, len(counts))
+	for key, count := range counts {
+		frequencies = append(frequencies, Frequency{Value: format(key), Count: count})
+	}
+
+	slices.SortFunc(frequencies, func(lhs, rhs Frequency) int {
+		if lhs.Count != rhs.Count {
+			return rhs.Count - lhs.Count
+		}
+		return cmp.Compare(lhs.Value, rhs.Value)
+	})
+
+	if n > 0 && len(frequencies) > n {
+		frequencies = frequencies[:n]
+	}
+
+	return frequencies
+}
+
+type counter struct {
+	runes     int
+	graphemes int
+	words     int
+	lines     int
+
+	letters    map[rune]int
+	wordCounts map[string]int
+
+	segmenter  graphemeSegmenter
+	lineLength int
+
+	word      strings.Builder
+	connector rune // apostrophe or hyphen that may continue the word
+}
+
+func newCounter() *counter {
+	return &counter{
+		letters:    make(map[rune]int),
+		wordCounts: make(map[string]int),
+	}
+}
+
+func (c *counter) add(symbol rune) {
+	c.runes++
+	if c.segmenter.breaks(symbol) {
+		c.graphemes++
+	}
+
+	if symbol == '\n' {
+		c.lines++
+		c.lineLength = 0
+	} else {
+		c.lineLength++
+	}
+
+	if unicode.IsLetter(symbol) {
+		c.letters[symbol]++
+	}
+
+	c.addToWord(symbol)
+}
+
+func isWordRune(symbol rune) bool {
+	return unicode.IsLetter(symbol) || unicode.IsDigit(symbol) || unicode.IsMark(symbol)
+}
+
+func isConnector(symbol rune) bool {
+	return symbol == '\'' || symbol == '’' || symbol == '-'
+}
+
+func (c *counter) addToWord(symbol rune) {
+	switch {
+	case isWordRune(symbol):
+		if c.connector != 0 {
+			c.word.WriteRune(c.connector)
+			c.connector = 0
+		}
+		c.word.WriteRune(symbol)
+	case isConnector(symbol) && c.word.Len() > 0 && c.connector == 0:
+		c.connector = symbol
+	default:
+		c.endWord()
+	}
+}
+
+func (c *counter) endWord() {
+	if c.word.Len() > 0 {
+		c.words++
+		c.wordCounts[c.word.String()]++
+		c.word.Reset()
+	}
+	c.connector = 0
+}
+
+func (c *counter) finish() {
+	c.endWord()
+	if c.lineLength > 0 {
+		c.lines++ // the last line without newline
+	}
+}
@@ -0,0 +1,102 @@
+package textstats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/unicode/norm"
+)
+
+// go test -v .
+
+func TestCounts(t *testing.T) {
+	tests := map[string]struct {
+		text      string
+		runes     int
+		graphemes int
+		words     int
+		lines     int
+	}{
+		"empty":              {text: "", runes: 0, graphemes: 0, words: 0, lines: 0},
+		"ascii":              {text: "hello world\n", runes: 12, graphemes: 12, words: 2, lines: 1},
+		"cyrillic":           {text: "Привет, мир!\nКак дела", runes: 21, graphemes: 21, words: 4, lines: 2},
+		"combining mark":     {text: "й", runes: 2, graphemes: 1, words: 1, lines: 1},
+		"crlf":               {text: "a\r\nb", runes: 4, graphemes: 3, words: 2, lines: 2},
+		"flags":              {text: "🇷🇺🇺🇸", runes: 4, graphemes: 2, words: 0, lines: 1},
+		"joined emoji":       {text: "👩‍💻", runes: 3, graphemes: 1, words: 0, lines: 1},
+		"emoji modifier":     {text: "👍🏽👍", runes: 3, graphemes: 2, words: 0, lines: 1},
+		"modified zwj emoji": {text: "👩🏽‍💻", runes: 4, graphemes: 1, words: 0, lines: 1},
+		"zwj without emoji":  {text: "a\u200db", runes: 3, graphemes: 2, words: 2, lines: 1},
+		"hangul jamo":        {text: "\u1100\u1161\u11a8\u1100", runes: 4, graphemes: 2, words: 1, lines: 1},
+		"hangul syllable":    {text: "가\u11a8각\u11a8", runes: 4, graphemes: 2, words: 1, lines: 1},
+		"devanagari":         {text: "क्षि नमस्ते", runes: 11, graphemes: 5, words: 2, lines: 1},
+		"thai spacing mark":  {text: "กำ", runes: 2, graphemes: 1, words: 1, lines: 1},
+		"prepend":            {text: "\u0600١", runes: 2, graphemes: 1, words: 1, lines: 1},
+		"control":            {text: "a\u0301\tb", runes: 4, graphemes: 3, words: 2, lines: 1},
+		"hyphen":             {text: "кто-то сказал - well-known", runes: 26, graphemes: 26, words: 3, lines: 1},
+		"apostrophe":         {text: "don't 'quoted'", runes: 14, graphemes: 14, words: 2, lines: 1},
+		"digits and letters": {text: "go1.24 версия", runes: 13, graphemes: 13, words: 3, lines: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := AnalyzeString(test.text)
+			assert.Equal(t, len(test.text), report.Bytes)
+			assert.Equal(t, test.runes, report.Runes)
+			assert.Equal(t, test.graphemes, report.Graphemes)
+			assert.Equal(t, test.words, report.Words)
+			assert.Equal(t, test.lines, report.Lines)
+		})
+	}
+}
+
+func TestNormalization(t *testing.T) {
+	decomposed := "йти"
+
+	report := AnalyzeString(decomposed)
+	assert.Equal(t, 4, report.Runes)
+	assert.Equal(t, 3, report.Graphemes)
+
+	report = AnalyzeString(decomposed, WithNormalization(norm.NFC))
+	assert.Equal(t, 3, report.Runes)
+	assert.Equal(t, []Frequency{{Value: "йти", Count: 1}}, report.TopWords)
+
+	// compatibility forms are replaced only by NFKC
+	report = AnalyzeString("ﬁ", WithNormalization(norm.NFC))
+	assert.Equal(t, "ﬁ", report.TopWords[0].Value)
+	report = AnalyzeString("ﬁ", WithNormalization(norm.NFKC))
+	assert.Equal(t, "fi", report.TopWords[0].Value)
+}
+
+func TestCaseFolding(t *testing.T) {
+	text := "Мир мир МИР World world"
+
+	report := AnalyzeString(text)
+	assert.Equal(t, 5, report.UniqueWords)
+
+	report = AnalyzeString(text, WithCaseFolding())
+	assert.Equal(t, 2, report.UniqueWords)
+	assert.Equal(t, []Frequency{{Value: "мир", Count: 3}, {Value: "world", Count: 2}}, report.TopWords)
+}
+
+func TestTopN(t *testing.T) {
+	report := AnalyzeString("ааабббв ггг", WithTopN(2))
+	assert.Equal(t, []Frequency{{Value: "а", Count: 3}, {Value: "б", Count: 3}}, report.Letters)
+
+	report = AnalyzeString("a b c d e f g h i j k l", WithTopN(0))
+	assert.Len(t, report.Letters, 12)
+	assert.Len(t, report.TopWords, 12)
+}
+
+func TestLargeInput(t *testing.T) {
+	text := strings.Repeat("съешь же ещё этих мягких французских булок\n", 10_000)
+	report, err := Analyze(strings.NewReader(text), WithTopN(3))
+	require.NoError(t, err)
+
+	assert.Equal(t, 10_000, report.Lines)
+	assert.Equal(t, 70_000, report.Words)
+	assert.Equal(t, 7, report.UniqueWords)
+	assert.Equal(t, Frequency{Value: "е", Count: 30_000}, report.Letters[0])
+}
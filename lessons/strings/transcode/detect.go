@@ -0,0 +1,129 @@
+package transcode
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var boms = []struct {
+	encoding Encoding
+	mark     []byte
+}{
+	{encoding: UTF8, mark: []byte{0xEF, 0xBB, 0xBF}},
+	{encoding: UTF16LE, mark: []byte{0xFF, 0xFE}},
+	{encoding: UTF16BE, mark: []byte{0xFE, 0xFF}},
+}
+
+// DetectBOM returns the encoding and the length of the byte order mark
+func DetectBOM(data []byte) (Encoding, int, bool) {
+	for _, bom := range boms {
+		if bytes.HasPrefix(data, bom.mark) {
+			return bom.encoding, len(bom.mark), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+func bomOf(encoding Encoding) []byte {
+	for _, bom := range boms {
+		if bom.encoding == encoding {
+			return bom.mark
+		}
+	}
+
+	return nil
+}
+
+// legacy encodings are checked in this order when scores are equal
+var guessCandidates = []Encoding{Windows1251, KOI8R, ISO8859_5, ISO8859_1}
+
+// Guess determines the encoding of the sample, for legacy 8-bit
+// encodings it prefers the one producing the most russian-like text
+func Guess(sample []byte) Encoding {
+	if encoding, _, found := DetectBOM(sample); found {
+		return encoding
+	}
+
+	// ASCII in UTF-16 is valid UTF-8 with zero bytes
+	if encoding, found := guessUTF16(sample); found {
+		return encoding
+	}
+
+	if utf8.Valid(sample) {
+		return UTF8
+	}
+
+	best, bestScore := ISO8859_1, 0
+	for _, candidate := range guessCandidates {
+		if score := cyrillicScore(candidate, sample); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	return best
+}
+
+// text in UTF-16 has a lot of zero bytes in one half of code units
+func guessUTF16(sample []byte) (Encoding, bool) {
+	if len(sample) < 2 {
+		return 0, false
+	}
+
+	var even, odd int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+
+	units := len(sample) / 2
+	switch {
+	case odd*10 > units*3 && even*10 < units:
+		return UTF16LE, true
+	case even*10 > units*3 && odd*10 < units:
+		return UTF16BE, true
+	default:
+		return 0, false
+	}
+}
+
+const frequentLetters = "оеаинтсрвлОЕАИНТСРВЛ"
+
+// cyrillicScore rewards frequent letters and penalizes capital letters
+// inside words and mixed scripts, which are typical for wrong tables
+func cyrillicScore(encoding Encoding, sample []byte) int {
+	decode, _, _ := encoding.codec()
+
+	score := 0
+	previous := ' '
+	for len(sample) > 0 {
+		r, size, st := decode(sample, true)
+		sample = sample[size:]
+		if st != statusOK {
+			score -= 5
+			continue
+		}
+
+		cyrillic := unicode.Is(unicode.Cyrillic, r)
+		switch {
+		case cyrillic && unicode.IsUpper(r) && unicode.IsLower(previous):
+			score -= 3
+		case cyrillic && unicode.Is(unicode.Latin, previous), unicode.Is(unicode.Latin, r) && unicode.Is(unicode.Cyrillic, previous):
+			score -= 3
+		case strings.ContainsRune(frequentLetters, r):
+			score += 2
+		case cyrillic:
+			score++
+		}
+
+		previous = r
+	}
+
+	return score
+}
@@ -0,0 +1,257 @@
+package transcode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF16LE
+	UTF16BE
+	Windows1251
+	KOI8R
+	ISO8859_1
+	ISO8859_2
+	ISO8859_3
+	ISO8859_4
+	ISO8859_5
+	ISO8859_6
+	ISO8859_7
+	ISO8859_8
+	ISO8859_9
+	ISO8859_10
+	ISO8859_13
+	ISO8859_14
+	ISO8859_15
+	ISO8859_16
+)
+
+var names = map[Encoding]string{
+	UTF8:        "UTF-8",
+	UTF16LE:     "UTF-16LE",
+	UTF16BE:     "UTF-16BE",
+	Windows1251: "Windows-1251",
+	KOI8R:       "KOI8-R",
+	ISO8859_1:   "ISO-8859-1",
+	ISO8859_2:   "ISO-8859-2",
+	ISO8859_3:   "ISO-8859-3",
+	ISO8859_4:   "ISO-8859-4",
+	ISO8859_5:   "ISO-8859-5",
+	ISO8859_6:   "ISO-8859-6",
+	ISO8859_7:   "ISO-8859-7",
+	ISO8859_8:   "ISO-8859-8",
+	ISO8859_9:   "ISO-8859-9",
+	ISO8859_10:  "ISO-8859-10",
+	ISO8859_13:  "ISO-8859-13",
+	ISO8859_14:  "ISO-8859-14",
+	ISO8859_15:  "ISO-8859-15",
+	ISO8859_16:  "ISO-8859-16",
+}
+
+var charmaps = map[Encoding]*charmap.Charmap{
+	Windows1251: charmap.Windows1251,
+	KOI8R:       charmap.KOI8R,
+	ISO8859_1:   charmap.ISO8859_1,
+	ISO8859_2:   charmap.ISO8859_2,
+	ISO8859_3:   charmap.ISO8859_3,
+	ISO8859_4:   charmap.ISO8859_4,
+	ISO8859_5:   charmap.ISO8859_5,
+	ISO8859_6:   charmap.ISO8859_6,
+	ISO8859_7:   charmap.ISO8859_7,
+	ISO8859_8:   charmap.ISO8859_8,
+	ISO8859_9:   charmap.ISO8859_9,
+	ISO8859_10:  charmap.ISO8859_10,
+	ISO8859_13:  charmap.ISO8859_13,
+	ISO8859_14:  charmap.ISO8859_14,
+	ISO8859_15:  charmap.ISO8859_15,
+	ISO8859_16:  charmap.ISO8859_16,
+}
+
+func (e Encoding) String() string {
+	if name, found := names[e]; found {
+		return name
+	}
+
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// ParseEncoding accepts names ignoring case, dashes and underscores
+func ParseEncoding(name string) (Encoding, error) {
+	simplify := strings.NewReplacer("-", "", "_", "", " ", "")
+	wanted := simplify.Replace(strings.ToLower(name))
+	for encoding, known := range names {
+		if simplify.Replace(strings.ToLower(known)) == wanted {
+			return encoding, nil
+		}
+	}
+
+	switch wanted {
+	case "cp1251":
+		return Windows1251, nil
+	case "latin1":
+		return ISO8859_1, nil
+	}
+
+	return 0, fmt.Errorf("unknown encoding %q", name)
+}
+
+type status int
+
+const (
+	statusOK status = iota
+	statusInvalid
+	statusShort // more input is needed
+)
+
+type decodeFunc func(src []byte, atEOF bool) (rune, int, status)
+
+type encodeFunc func(dst []byte, r rune) ([]byte, bool)
+
+func (e Encoding) codec() (decodeFunc, encodeFunc, error) {
+	switch e {
+	case UTF8:
+		return decodeUTF8, encodeUTF8, nil
+	case UTF16LE:
+		return decodeUTF16(binary.LittleEndian), encodeUTF16(binary.LittleEndian), nil
+	case UTF16BE:
+		return decodeUTF16(binary.BigEndian), encodeUTF16(binary.BigEndian), nil
+	}
+
+	table, found := charmaps[e]
+	if !found {
+		return nil, nil, fmt.Errorf("unknown encoding %v", e)
+	}
+
+	decode := func(src []byte, _ bool) (rune, int, status) {
+		// undefined bytes are decoded as utf8.RuneError
+		if r := table.DecodeByte(src[0]); r != utf8.RuneError {
+			return r, 1, statusOK
+		}
+		return utf8.RuneError, 1, statusInvalid
+	}
+
+	encode := func(dst []byte, r rune) ([]byte, bool) {
+		b, ok := table.EncodeRune(r)
+		if !ok {
+			return dst, false
+		}
+		return append(dst, b), true
+	}
+
+	return decode, encode, nil
+}
+
+func decodeUTF8(src []byte, atEOF bool) (rune, int, status) {
+	if !atEOF && !utf8.FullRune(src) {
+		return 0, 0, statusShort
+	}
+
+	r, size := utf8.DecodeRune(src)
+	if r == utf8.RuneError && size <= 1 {
+		return r, invalidUTF8Length(src), statusInvalid
+	}
+
+	return r, size, statusOK
+}
+
+// invalidUTF8Length returns the length of the maximal prefix
+// of a valid sequence, it is replaced as a whole like in browsers
+func invalidUTF8Length(src []byte) int {
+	var need int
+	low, high := byte(0x80), byte(0xBF)
+	switch lead := src[0]; {
+	case lead >= 0xC2 && lead <= 0xDF:
+		need = 1
+	case lead >= 0xE0 && lead <= 0xEF:
+		need = 2
+		if lead == 0xE0 {
+			low = 0xA0
+		} else if lead == 0xED {
+			high = 0x9F // surrogates
+		}
+	case lead >= 0xF0 && lead <= 0xF4:
+		need = 3
+		if lead == 0xF0 {
+			low = 0x90
+		} else if lead == 0xF4 {
+			high = 0x8F
+		}
+	default:
+		return 1
+	}
+
+	length := 1
+	for ; length <= need && length < len(src); length++ {
+		if src[length] < low || src[length] > high {
+			break
+		}
+		low, high = 0x80, 0xBF
+	}
+
+	return length
+}
+
+func encodeUTF8(dst []byte, r rune) ([]byte, bool) {
+	if !utf8.ValidRune(r) {
+		return dst, false
+	}
+
+	return utf8.AppendRune(dst, r), true
+}
+
+func decodeUTF16(order binary.ByteOrder) decodeFunc {
+	return func(src []byte, atEOF bool) (rune, int, status) {
+		if len(src) < 2 {
+			if atEOF {
+				return utf8.RuneError, len(src), statusInvalid
+			}
+			return 0, 0, statusShort
+		}
+
+		first := rune(order.Uint16(src))
+		if !utf16.IsSurrogate(first) {
+			return first, 2, statusOK
+		}
+
+		if first >= 0xDC00 {
+			return utf8.RuneError, 2, statusInvalid // low surrogate without high one
+		}
+
+		if len(src) < 4 {
+			if atEOF {
+				return utf8.RuneError, 2, statusInvalid
+			}
+			return 0, 0, statusShort
+		}
+
+		second := rune(order.Uint16(src[2:]))
+		if r := utf16.DecodeRune(first, second); r != utf8.RuneError {
+			return r, 4, statusOK
+		}
+
+		return utf8.RuneError, 2, statusInvalid
+	}
+}
+
+func encodeUTF16(order binary.AppendByteOrder) encodeFunc {
+	return func(dst []byte, r rune) ([]byte, bool) {
+		if !utf8.ValidRune(r) {
+			return dst, false
+		}
+
+		if first, second := utf16.EncodeRune(r); first != utf8.RuneError {
+			dst = order.AppendUint16(dst, uint16(first))
+			return order.AppendUint16(dst, uint16(second)), true
+		}
+
+		return order.AppendUint16(dst, uint16(r)), true
+	}
+}
@@ -0,0 +1,212 @@
+package transcode
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// go test -v .
+
+const russian = "Съешь же ещё этих мягких французских булок, да выпей чаю"
+
+func encodeWith(t *testing.T, table *charmap.Charmap, text string) []byte {
+	encoded, err := table.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		encoding Encoding
+		text     string
+	}{
+		"utf-16le":     {encoding: UTF16LE, text: russian + " 🙂"},
+		"utf-16be":     {encoding: UTF16BE, text: russian + " 🙂"},
+		"windows-1251": {encoding: Windows1251, text: russian},
+		"koi8-r":       {encoding: KOI8R, text: russian},
+		"iso-8859-5":   {encoding: ISO8859_5, text: russian},
+		"iso-8859-1":   {encoding: ISO8859_1, text: "Größe café"},
+		"iso-8859-15":  {encoding: ISO8859_15, text: "prix 10€"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := New(UTF8, test.encoding)
+			require.NoError(t, err)
+			encoded, report, err := encoder.Bytes([]byte(test.text))
+			require.NoError(t, err)
+			assert.Zero(t, report.Unencodable)
+
+			decoder, err := New(test.encoding, UTF8)
+			require.NoError(t, err)
+
+			// reading byte by byte checks sequences split between reads
+			var output bytes.Buffer
+			report, err = decoder.Transcode(&output, iotest.OneByteReader(bytes.NewReader(encoded)))
+			require.NoError(t, err)
+			assert.Equal(t, test.text, output.String())
+			assert.Equal(t, int64(len(encoded)), report.BytesRead)
+			assert.Equal(t, int64(len(test.text)), report.BytesWritten)
+		})
+	}
+}
+
+func TestMatchesCharmap(t *testing.T) {
+	encoder, err := New(UTF8, KOI8R)
+	require.NoError(t, err)
+
+	encoded, _, err := encoder.Bytes([]byte(russian))
+	require.NoError(t, err)
+	assert.Equal(t, encodeWith(t, charmap.KOI8R, russian), encoded)
+}
+
+func TestBOM(t *testing.T) {
+	withBOM, err := New(UTF8, UTF16BE, WithBOM())
+	require.NoError(t, err)
+	encoded, _, err := withBOM.Bytes([]byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, encoded)
+
+	// the BOM overrides the requested UTF-16 byte order
+	decoder, err := New(UTF16LE, UTF8)
+	require.NoError(t, err)
+	decoded, report, err := decoder.Bytes(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(decoded))
+	assert.Equal(t, UTF16BE, report.From)
+
+	// legacy encodings don't have BOMs, 0xFF 0xFE is "яю" in Windows-1251
+	legacy, err := New(Windows1251, UTF8)
+	require.NoError(t, err)
+	decoded, _, err = legacy.Bytes([]byte{0xFF, 0xFE})
+	require.NoError(t, err)
+	assert.Equal(t, "яю", string(decoded))
+}
+
+func TestInvalidInput(t *testing.T) {
+	data := []byte("ok\xffok\xe2\x82ok\xf0")
+
+	errs := Validate(data)
+	require.Len(t, errs, 3)
+	assert.Equal(t, int64(2), errs[0].Offset)
+	assert.Equal(t, []byte{0xFF}, errs[0].Bytes)
+	assert.Equal(t, int64(5), errs[1].Offset)
+	assert.Equal(t, []byte{0xE2, 0x82}, errs[1].Bytes)
+	assert.Equal(t, int64(9), errs[2].Offset)
+	assert.Equal(t, "invalid sequence ff at offset 2", errs[0].Error())
+
+	assert.Equal(t, "ok?ok?ok?", string(Repair(data, '?')))
+	assert.Empty(t, Validate([]byte(russian)))
+}
+
+func TestInvalidUTF16(t *testing.T) {
+	// lone low surrogate, then high surrogate without a pair
+	data := []byte{'a', 0, 0x00, 0xDC, 0x00, 0xD8, 'b', 0, 'c'}
+	decoder, err := New(UTF16LE, UTF8)
+	require.NoError(t, err)
+
+	decoded, report, err := decoder.Bytes(data)
+	require.NoError(t, err)
+	assert.Equal(t, "a��b�", string(decoded))
+	assert.Equal(t, 3, report.InvalidSequences)
+	assert.Equal(t, int64(2), report.Errors[0].Offset)
+	assert.Equal(t, int64(4), report.Errors[1].Offset)
+	assert.Equal(t, int64(8), report.Errors[2].Offset)
+}
+
+func TestUnencodable(t *testing.T) {
+	encoder, err := New(UTF8, Windows1251)
+	require.NoError(t, err)
+
+	encoded, report, err := encoder.Bytes([]byte("да 日本"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xE4, 0xE0, ' ', '?', '?'}, encoded)
+	assert.Equal(t, 2, report.Unencodable)
+	assert.Equal(t, int64(5), report.Errors[0].Offset)
+	assert.Equal(t, '日', report.Errors[0].Rune)
+
+	encoder, err = New(UTF8, Windows1251, WithReplacement('_'))
+	require.NoError(t, err)
+	encoded, _, err = encoder.Bytes([]byte("日"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("_"), encoded)
+}
+
+func TestStrict(t *testing.T) {
+	decoder, err := New(UTF8, UTF16LE, WithStrict())
+	require.NoError(t, err)
+
+	var output bytes.Buffer
+	_, err = decoder.Transcode(&output, strings.NewReader("ab\xffcd"))
+
+	var transcodeErr *Error
+	require.ErrorAs(t, err, &transcodeErr)
+	assert.Equal(t, int64(2), transcodeErr.Offset)
+	assert.Equal(t, []byte{'a', 0, 'b', 0}, output.Bytes())
+}
+
+func TestReadError(t *testing.T) {
+	decoder, err := New(UTF8, UTF8)
+	require.NoError(t, err)
+
+	expected := errors.New("disk failure")
+	_, err = decoder.Transcode(io.Discard, iotest.ErrReader(expected))
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestGuess(t *testing.T) {
+	utf16, _, err := func() ([]byte, Report, error) {
+		encoder, _ := New(UTF8, UTF16LE)
+		return encoder.Bytes([]byte("plain text"))
+	}()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		sample   []byte
+		expected Encoding
+	}{
+		"utf-8":        {sample: []byte(russian), expected: UTF8},
+		"bom":          {sample: []byte{0xFE, 0xFF, 0, 'a'}, expected: UTF16BE},
+		"utf-16le":     {sample: utf16, expected: UTF16LE},
+		"windows-1251": {sample: encodeWith(t, charmap.Windows1251, russian), expected: Windows1251},
+		"koi8-r":       {sample: encodeWith(t, charmap.KOI8R, russian), expected: KOI8R},
+		"iso-8859-5":   {sample: encodeWith(t, charmap.ISO8859_5, russian), expected: ISO8859_5},
+		"latin-1":      {sample: encodeWith(t, charmap.ISO8859_1, "Größe"), expected: ISO8859_1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Guess(test.sample))
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := map[string]Encoding{
+		"utf-8":        UTF8,
+		"UTF16LE":      UTF16LE,
+		"windows-1251": Windows1251,
+		"cp1251":       Windows1251,
+		"koi8_r":       KOI8R,
+		"ISO-8859-5":   ISO8859_5,
+		"latin1":       ISO8859_1,
+	}
+
+	for name, expected := range tests {
+		encoding, err := ParseEncoding(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, encoding)
+	}
+
+	_, err := ParseEncoding("ebcdic")
+	assert.Error(t, err)
+	assert.Equal(t, "KOI8-R", KOI8R.String())
+}
@@ -0,0 +1,270 @@
+package transcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+const (
+	chunkSize        = 32 << 10
+	defaultMaxErrors = 100
+	fallbackRune     = '?'
+)
+
+type ErrorKind int
+
+const (
+	InvalidSequence ErrorKind = iota // input bytes are not valid in the source encoding
+	Unencodable                      // the rune doesn't exist in the target encoding
+)
+
+func (k ErrorKind) String() string {
+	if k == Unencodable {
+		return "unencodable rune"
+	}
+
+	return "invalid sequence"
+}
+
+type Error struct {
+	Offset int64 // in the input including BOM
+	Kind   ErrorKind
+	Bytes  []byte // invalid input bytes
+	Rune   rune   // unencodable rune
+}
+
+func (e *Error) Error() string {
+	if e.Kind == Unencodable {
+		return fmt.Sprintf("%v %q at offset %d", e.Kind, e.Rune, e.Offset)
+	}
+
+	return fmt.Sprintf("%v % x at offset %d", e.Kind, e.Bytes, e.Offset)
+}
+
+type Option func(*options)
+
+type options struct {
+	replacement rune
+	strict      bool
+	writeBOM    bool
+	detectBOM   bool
+	maxErrors   int
+}
+
+// WithReplacement sets the rune written instead of invalid or unencodable
+// input, '?' is used if the target encoding can't represent it
+func WithReplacement(replacement rune) Option {
+	return func(o *options) {
+		o.replacement = replacement
+	}
+}
+
+// WithStrict stops on the first error instead of replacing
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithBOM writes the byte order mark if the target is UTF-8 or UTF-16
+func WithBOM() Option {
+	return func(o *options) {
+		o.writeBOM = true
+	}
+}
+
+// WithoutBOMDetection keeps the source encoding even if the input starts with a BOM
+func WithoutBOMDetection() Option {
+	return func(o *options) {
+		o.detectBOM = false
+	}
+}
+
+// WithMaxErrors limits the number of errors kept in the report, they are still counted
+func WithMaxErrors(count int) Option {
+	return func(o *options) {
+		o.maxErrors = count
+	}
+}
+
+type Report struct {
+	From             Encoding // may differ from the requested one after BOM detection
+	BytesRead        int64
+	BytesWritten     int64
+	InvalidSequences int
+	Unencodable      int
+	Errors           []Error
+}
+
+type Transcoder struct {
+	from    Encoding
+	to      Encoding
+	options options
+}
+
+func New(from, to Encoding, opts ...Option) (*Transcoder, error) {
+	o := options{
+		replacement: utf8.RuneError,
+		detectBOM:   true,
+		maxErrors:   defaultMaxErrors,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for _, encoding := range []Encoding{from, to} {
+		if _, _, err := encoding.codec(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Transcoder{from: from, to: to, options: o}, nil
+}
+
+func isUnicode(encoding Encoding) bool {
+	return encoding == UTF8 || encoding == UTF16LE || encoding == UTF16BE
+}
+
+// Transcode converts the stream chunk by chunk, sequences
+// split between reads are handled
+func (t *Transcoder) Transcode(dst io.Writer, src io.Reader) (Report, error) {
+	report := Report{From: t.from}
+	decode, _, _ := t.from.codec()
+	_, encode, _ := t.to.codec()
+
+	var output []byte
+	if t.options.writeBOM && isUnicode(t.to) {
+		output = append(output, bomOf(t.to)...)
+	}
+
+	flush := func() error {
+		written, err := dst.Write(output)
+		report.BytesWritten += int64(written)
+		output = output[:0]
+		return err
+	}
+
+	record := func(err Error) error {
+		if err.Kind == Unencodable {
+			report.Unencodable++
+		} else {
+			report.InvalidSequences++
+		}
+
+		if len(report.Errors) < t.options.maxErrors {
+			report.Errors = append(report.Errors, err)
+		}
+
+		if t.options.strict {
+			return &err
+		}
+		return nil
+	}
+
+	replace := func(output []byte) []byte {
+		if result, ok := encode(output, t.options.replacement); ok {
+			return result
+		}
+		result, _ := encode(output, fallbackRune)
+		return result
+	}
+
+	var pending []byte
+	var offset int64 // of the first pending byte
+	buffer := make([]byte, chunkSize)
+	detectBOM := t.options.detectBOM && isUnicode(t.from)
+	for atEOF := false; !atEOF; {
+		n, err := src.Read(buffer)
+		report.BytesRead += int64(n)
+		pending = append(pending, buffer[:n]...)
+		if errors.Is(err, io.EOF) {
+			atEOF = true
+		} else if err != nil {
+			return report, err
+		}
+
+		if detectBOM {
+			if len(pending) < 3 && !atEOF {
+				continue
+			}
+
+			if encoding, size, found := DetectBOM(pending); found {
+				report.From = encoding
+				decode, _, _ = encoding.codec()
+				pending = pending[size:]
+				offset += int64(size)
+			}
+			detectBOM = false
+		}
+
+		consumed := 0
+		for consumed < len(pending) {
+			r, size, st := decode(pending[consumed:], atEOF)
+			if st == statusShort {
+				break
+			}
+
+			if st == statusInvalid {
+				invalid := Error{
+					Offset: offset + int64(consumed),
+					Kind:   InvalidSequence,
+					Bytes:  slices.Clone(pending[consumed : consumed+size]),
+				}
+
+				if err := record(invalid); err != nil {
+					return report, errors.Join(err, flush())
+				}
+
+				output = replace(output)
+				consumed += size
+				continue
+			}
+
+			var ok bool
+			if output, ok = encode(output, r); !ok {
+				unencodable := Error{Offset: offset + int64(consumed), Kind: Unencodable, Rune: r}
+				if err := record(unencodable); err != nil {
+					return report, errors.Join(err, flush())
+				}
+
+				output = replace(output)
+			}
+
+			consumed += size
+		}
+
+		if err := flush(); err != nil {
+			return report, err
+		}
+
+		pending = pending[:copy(pending, pending[consumed:])]
+		offset += int64(consumed)
+	}
+
+	return report, nil
+}
+
+func (t *Transcoder) Bytes(data []byte) ([]byte, Report, error) {
+	var output bytes.Buffer
+	report, err := t.Transcode(&output, bytes.NewReader(data))
+	return output.Bytes(), report, err
+}
+
+// Validate returns all invalid UTF-8 sequences of the data
+func Validate(data []byte) []Error {
+	t, _ := New(UTF8, UTF8, WithoutBOMDetection(), WithMaxErrors(len(data)))
+	_, report, _ := t.Bytes(data)
+	return report.Errors
+}
+
+// Repair replaces invalid UTF-8 sequences with the replacement rune
+func Repair(data []byte, replacement rune) []byte {
+	t, _ := New(UTF8, UTF8, WithoutBOMDetection(), WithReplacement(replacement))
+	output, _, _ := t.Bytes(data)
+	return output
+}
@@ -0,0 +1,189 @@
+package bitfield
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+// GamePersonParams mirrors params1/params2 of GamePerson from homework/structs
+type GamePersonParams struct {
+	Mana       uint16 `bits:"10,max=1000"`
+	Health     uint16 `bits:"10,max=1000"`
+	Respect    uint8  `bits:"4,max=10"`
+	Strength   uint8  `bits:"4,max=10"`
+	Experience uint8  `bits:"4,max=10"`
+	Level      uint8  `bits:"4,max=10"`
+	House      bool   `bits:"1"`
+	Gun        bool   `bits:"1"`
+	Family     bool   `bits:"1"`
+	Type       uint8  `bits:"2,max=2"`
+	Comment    string // not packed
+}
+
+func TestTags(t *testing.T) {
+	params := GamePersonParams{
+		Mana:       1000,
+		Health:     1,
+		Respect:    10,
+		Strength:   7,
+		Experience: 0,
+		Level:      3,
+		Gun:        true,
+		Family:     true,
+		Type:       2,
+		Comment:    "ignored",
+	}
+
+	record, err := Pack(&params)
+	require.NoError(t, err)
+	assert.Len(t, record, 6) // 41 bits
+
+	var result GamePersonParams
+	require.NoError(t, Unpack(record, &result))
+	params.Comment = ""
+	assert.Equal(t, params, result)
+
+	layout, err := LayoutOf(reflect.TypeOf(params))
+	require.NoError(t, err)
+	offset, found := layout.Offset("Type")
+	assert.True(t, found)
+	assert.Equal(t, uint(39), offset)
+}
+
+func TestTagsValidation(t *testing.T) {
+	_, err := Pack(GamePersonParams{Mana: 1001})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	_, err = Pack(GamePersonParams{Type: 3})
+	assert.ErrorIs(t, err, ErrOutOfRange)
+
+	record := make([]byte, 6)
+	record[0], record[1] = 0xFF, 0x03 // mana = 1023
+	assert.ErrorIs(t, Unpack(record, &GamePersonParams{}), ErrOutOfRange)
+	assert.ErrorIs(t, Unpack(record[:2], &GamePersonParams{}), ErrShortBuffer)
+	assert.Error(t, Unpack(record, GamePersonParams{}))
+
+	invalid := []any{
+		struct {
+			Name string `bits:"8"`
+		}{},
+		struct {
+			Small uint8 `bits:"9"`
+		}{},
+		struct {
+			Value uint8 `bits:"4,max=16"`
+		}{},
+		struct {
+			Value uint8 `bits:"4,step=2"`
+		}{},
+		struct {
+			Value int8 `bits:"x"`
+		}{},
+		struct {
+			value uint8 `bits:"4"`
+		}{},
+	}
+
+	for _, value := range invalid {
+		_, err := Pack(value)
+		assert.ErrorIs(t, err, ErrInvalidLayout, "%T", value)
+	}
+
+	unexported := struct {
+		Value  uint8 `bits:"4"`
+		hidden uint8 `bits:"4"`
+	}{}
+	assert.ErrorIs(t, Unpack([]byte{0xFF}, &unexported), ErrInvalidLayout)
+}
+
+// must unwraps results of getters, a failed test panics
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+func TestBuilder(t *testing.T) {
+	builder := NewBuilder()
+	mana := AddField[uint16](builder, "mana", 10, WithRange(0, 1000))
+	delta := AddField[int8](builder, "delta", 5)
+	house := AddFlag(builder, "house")
+	big := AddField[int64](builder, "big", 64)
+	layout := builder.MustBuild()
+	assert.Equal(t, uint(80), layout.Bits())
+
+	record := layout.New()
+	require.NoError(t, mana.Set(record, 999))
+	require.NoError(t, delta.Set(record, -16))
+	require.NoError(t, house.Set(record, true))
+	require.NoError(t, big.Set(record, math.MinInt64+1))
+
+	assert.Equal(t, uint16(999), must(mana.Get(record)))
+	assert.Equal(t, int8(-16), must(delta.Get(record)))
+	assert.True(t, must(house.Get(record)))
+	assert.Equal(t, int64(math.MinInt64+1), must(big.Get(record)))
+
+	require.NoError(t, delta.Set(record, 15))
+	require.NoError(t, house.Set(record, false))
+	assert.Equal(t, int8(15), must(delta.Get(record)))
+	assert.False(t, must(house.Get(record)))
+	assert.Equal(t, uint16(999), must(mana.Get(record)))
+
+	assert.ErrorIs(t, mana.Set(record, 1001), ErrOutOfRange)
+	assert.ErrorIs(t, delta.Set(record, 16), ErrOutOfRange)
+	assert.ErrorIs(t, delta.Set(record, -17), ErrOutOfRange)
+	assert.Equal(t, "mana", mana.Name())
+
+	short := record[:2]
+	_, err := big.Get(short)
+	assert.ErrorIs(t, err, ErrShortBuffer)
+	assert.ErrorIs(t, big.Set(short, 1), ErrShortBuffer)
+	_, err = house.Get(short[:1])
+	assert.ErrorIs(t, err, ErrShortBuffer)
+	assert.ErrorIs(t, house.Set(short[:1], true), ErrShortBuffer)
+	assert.Equal(t, uint16(999), must(mana.Get(short)))
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := map[string]func(*Builder){
+		"zero width": func(b *Builder) {
+			AddField[uint8](b, "value", 0)
+		},
+		"too wide": func(b *Builder) {
+			AddField[uint64](b, "value", 65)
+		},
+		"wider than the type": func(b *Builder) {
+			AddField[uint8](b, "value", 12)
+		},
+		"wider than the signed type": func(b *Builder) {
+			AddField[int16](b, "value", 17)
+		},
+		"duplicate": func(b *Builder) {
+			AddFlag(b, "value")
+			AddFlag(b, "value")
+		},
+		"range does not fit": func(b *Builder) {
+			AddField[uint16](b, "value", 4, WithRange(0, 16))
+		},
+		"inverted range": func(b *Builder) {
+			AddField[uint16](b, "value", 4, WithRange(5, 1))
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := NewBuilder()
+			test(builder)
+			_, err := builder.Build()
+			assert.ErrorIs(t, err, ErrInvalidLayout)
+		})
+	}
+}
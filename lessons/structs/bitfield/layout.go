@@ -0,0 +1,274 @@
+package bitfield
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+var (
+	ErrOutOfRange    = errors.New("value out of range")
+	ErrInvalidLayout = errors.New("invalid layout")
+	ErrShortBuffer   = errors.New("buffer is shorter than the layout")
+)
+
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// spec describes where a field lives: bit offset counts from
+// the least significant bit of the first byte
+type spec struct {
+	name     string
+	offset   uint
+	width    uint
+	min, max int64
+	signed   bool
+}
+
+func (s spec) check(value int64) error {
+	if value < s.min || value > s.max {
+		return fmt.Errorf("%w: %s = %d, expected [%d; %d]", ErrOutOfRange, s.name, value, s.min, s.max)
+	}
+
+	return nil
+}
+
+// fits reports whether the record is long enough to hold the field
+func (s spec) fits(data []byte) error {
+	if uint(len(data))*8 < s.offset+s.width {
+		return fmt.Errorf("%w: %s needs %d bytes", ErrShortBuffer, s.name, (s.offset+s.width+7)/8)
+	}
+
+	return nil
+}
+
+func (s spec) read(data []byte) int64 {
+	var raw uint64
+	for i := uint(0); i < s.width; {
+		bit := s.offset + i
+		chunk := min(8-bit%8, s.width-i)
+		part := uint64(data[bit/8]>>(bit%8)) & (1<<chunk - 1)
+		raw |= part << i
+		i += chunk
+	}
+
+	if s.signed && s.width < 64 && raw&(1<<(s.width-1)) != 0 {
+		raw |= ^uint64(0) << s.width // sign extension
+	}
+
+	return int64(raw)
+}
+
+func (s spec) write(data []byte, value int64) {
+	raw := uint64(value)
+	for i := uint(0); i < s.width; {
+		bit := s.offset + i
+		chunk := min(8-bit%8, s.width-i)
+		mask := byte(1<<chunk-1) << (bit % 8)
+		data[bit/8] = data[bit/8]&^mask | byte(raw>>i)<<(bit%8)&mask
+		i += chunk
+	}
+}
+
+func widthRange(width uint, signed bool) (int64, int64) {
+	if signed {
+		return -1 << (width - 1), 1<<(width-1) - 1
+	}
+
+	if width == 64 {
+		return 0, -1 >> 1 // values are validated as int64
+	}
+
+	return 0, 1<<width - 1
+}
+
+// Layout is an ordered set of fields packed without gaps
+type Layout struct {
+	fields []spec
+	index  map[string]int
+	bits   uint
+}
+
+func (l *Layout) Bits() uint {
+	return l.bits
+}
+
+// Size returns the number of bytes needed to store a record
+func (l *Layout) Size() int {
+	return int((l.bits + 7) / 8)
+}
+
+func (l *Layout) New() []byte {
+	return make([]byte, l.Size())
+}
+
+// Offset returns the bit offset of the field, it helps to document layouts
+func (l *Layout) Offset(name string) (uint, bool) {
+	i, found := l.index[name]
+	if !found {
+		return 0, false
+	}
+
+	return l.fields[i].offset, true
+}
+
+func (l *Layout) add(s spec) error {
+	if s.width == 0 || s.width > 64 {
+		return fmt.Errorf("%w: field %s has width %d", ErrInvalidLayout, s.name, s.width)
+	}
+
+	if _, found := l.index[s.name]; found {
+		return fmt.Errorf("%w: duplicate field %s", ErrInvalidLayout, s.name)
+	}
+
+	low, high := widthRange(s.width, s.signed)
+	if s.min < low || s.max > high || s.min > s.max {
+		return fmt.Errorf("%w: range [%d; %d] of %s does not fit into %d bits", ErrInvalidLayout, s.min, s.max, s.name, s.width)
+	}
+
+	s.offset = l.bits
+	l.index[s.name] = len(l.fields)
+	l.fields = append(l.fields, s)
+	l.bits += s.width
+	return nil
+}
+
+type FieldOption func(*spec)
+
+// WithRange narrows the accepted values, by default any value fitting into the width is accepted
+func WithRange(min, max int64) FieldOption {
+	return func(s *spec) {
+		s.min, s.max = min, max
+	}
+}
+
+type Builder struct {
+	layout *Layout
+	err    error
+}
+
+func NewBuilder() *Builder {
+	return &Builder{
+		layout: &Layout{index: make(map[string]int)},
+	}
+}
+
+// Build returns the first error met while adding fields
+func (b *Builder) Build() (*Layout, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.layout, nil
+}
+
+func (b *Builder) MustBuild() *Layout {
+	layout, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return layout
+}
+
+func (b *Builder) add(name string, width, typeBits uint, signed bool, opts []FieldOption) spec {
+	if width > typeBits && b.err == nil {
+		b.err = fmt.Errorf("%w: field %s has invalid width %d", ErrInvalidLayout, name, width)
+	}
+
+	s := spec{name: name, width: width, signed: signed}
+	if width > 0 && width <= 64 {
+		s.min, s.max = widthRange(width, signed)
+	}
+
+	for _, option := range opts {
+		option(&s)
+	}
+
+	if b.err == nil {
+		b.err = b.layout.add(s)
+	}
+
+	if b.err != nil {
+		return spec{}
+	}
+
+	return b.layout.fields[len(b.layout.fields)-1]
+}
+
+// Field is a typed accessor to a field of records built from one Layout
+type Field[T Integer] struct {
+	spec spec
+}
+
+func AddField[T Integer](b *Builder, name string, width uint, opts ...FieldOption) Field[T] {
+	var zero T
+	return Field[T]{spec: b.add(name, width, uint(unsafe.Sizeof(zero))*8, ^zero < 0, opts)}
+}
+
+func (f Field[T]) Name() string {
+	return f.spec.name
+}
+
+func (f Field[T]) Get(record []byte) (T, error) {
+	if err := f.spec.fits(record); err != nil {
+		return 0, err
+	}
+
+	return T(f.spec.read(record)), nil
+}
+
+func (f Field[T]) Set(record []byte, value T) error {
+	if err := f.spec.fits(record); err != nil {
+		return err
+	}
+
+	number := int64(value)
+	if !f.spec.signed && number < 0 {
+		// uint64 values above MaxInt64
+		return fmt.Errorf("%w: %s = %d", ErrOutOfRange, f.spec.name, uint64(value))
+	}
+
+	if err := f.spec.check(number); err != nil {
+		return err
+	}
+
+	f.spec.write(record, number)
+	return nil
+}
+
+type Flag struct {
+	spec spec
+}
+
+func AddFlag(b *Builder, name string) Flag {
+	return Flag{spec: b.add(name, 1, 1, false, nil)}
+}
+
+func (f Flag) Name() string {
+	return f.spec.name
+}
+
+func (f Flag) Get(record []byte) (bool, error) {
+	if err := f.spec.fits(record); err != nil {
+		return false, err
+	}
+
+	return f.spec.read(record) != 0, nil
+}
+
+func (f Flag) Set(record []byte, value bool) error {
+	if err := f.spec.fits(record); err != nil {
+		return err
+	}
+
+	var number int64
+	if value {
+		number = 1
+	}
+
+	f.spec.write(record, number)
+	return nil
+}
@@ -0,0 +1,179 @@
+package bitfield
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var layouts sync.Map // reflect.Type -> *Layout
+
+// LayoutOf builds a layout from struct tags, fields without the tag are skipped:
+//
+//	type Params struct {
+//		Mana  uint16 `bits:"10,max=1000"`
+//		Type  uint8  `bits:"2,min=0,max=2"`
+//		House bool   `bits:"1"`
+//	}
+func LayoutOf(t reflect.Type) (*Layout, error) {
+	if cached, found := layouts.Load(t); found {
+		return cached.(*Layout), nil
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrInvalidLayout, t)
+	}
+
+	layout := &Layout{index: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, found := field.Tag.Lookup("bits")
+		if !found {
+			continue
+		}
+
+		s, err := parseTag(field, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := layout.add(s); err != nil {
+			return nil, err
+		}
+	}
+
+	layouts.Store(t, layout)
+	return layout, nil
+}
+
+func parseTag(field reflect.StructField, tag string) (spec, error) {
+	// unexported fields can't be set by reflection
+	if !field.IsExported() {
+		return spec{}, fmt.Errorf("%w: field %s is unexported", ErrInvalidLayout, field.Name)
+	}
+
+	s := spec{name: field.Name}
+	typeBits := uint64(1)
+	switch field.Type.Kind() {
+	case reflect.Bool:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.signed = true
+		typeBits = uint64(field.Type.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		typeBits = uint64(field.Type.Bits())
+	default:
+		return spec{}, fmt.Errorf("%w: field %s has unsupported type %s", ErrInvalidLayout, field.Name, field.Type)
+	}
+
+	parts := strings.Split(tag, ",")
+	width, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil || width == 0 || width > typeBits {
+		return spec{}, fmt.Errorf("%w: field %s has invalid width %q", ErrInvalidLayout, field.Name, parts[0])
+	}
+
+	s.width = uint(width)
+	s.min, s.max = widthRange(s.width, s.signed)
+	for _, option := range parts[1:] {
+		key, value, _ := strings.Cut(option, "=")
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return spec{}, fmt.Errorf("%w: field %s has invalid option %q", ErrInvalidLayout, field.Name, option)
+		}
+
+		switch key {
+		case "min":
+			s.min = number
+		case "max":
+			s.max = number
+		default:
+			return spec{}, fmt.Errorf("%w: field %s has unknown option %q", ErrInvalidLayout, field.Name, key)
+		}
+	}
+
+	return s, nil
+}
+
+func structValue(value any) (reflect.Value, *Layout, error) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return reflect.Value{}, nil, errors.New("bitfield: nil value")
+	}
+
+	layout, err := LayoutOf(v.Type())
+	return v, layout, err
+}
+
+// Pack validates every tagged field and packs them into a compact record
+func Pack(value any) ([]byte, error) {
+	v, layout, err := structValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	record := layout.New()
+	for _, s := range layout.fields {
+		field := v.FieldByName(s.name)
+
+		var number int64
+		switch field.Kind() {
+		case reflect.Bool:
+			if field.Bool() {
+				number = 1
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			number = field.Int()
+		default:
+			if field.Uint() > uint64(s.max) {
+				return nil, fmt.Errorf("%w: %s = %d, expected [%d; %d]", ErrOutOfRange, s.name, field.Uint(), s.min, s.max)
+			}
+
+			number = int64(field.Uint())
+		}
+
+		if err := s.check(number); err != nil {
+			return nil, err
+		}
+
+		s.write(record, number)
+	}
+
+	return record, nil
+}
+
+// Unpack fills tagged fields of the struct pointed to by pointer
+func Unpack(record []byte, pointer any) error {
+	if v := reflect.ValueOf(pointer); v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("bitfield: unpack requires a non-nil pointer")
+	}
+
+	v, layout, err := structValue(pointer)
+	if err != nil {
+		return err
+	}
+
+	if len(record) < layout.Size() {
+		return ErrShortBuffer
+	}
+
+	for _, s := range layout.fields {
+		number := s.read(record)
+		if err := s.check(number); err != nil {
+			return err
+		}
+
+		field := v.FieldByName(s.name)
+		switch field.Kind() {
+		case reflect.Bool:
+			field.SetBool(number != 0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(number)
+		default:
+			field.SetUint(uint64(number))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type FieldLayout struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	Align  int64  `json:"align"`
+	// padding inserted after the field
+	Padding int64 `json:"padding"`
+	// set for fields crossing a cache line boundary
+	Straddles bool `json:"straddles,omitempty"`
+}
+
+type StructReport struct {
+	Package        string        `json:"package"`
+	Name           string        `json:"name"`
+	Position       string        `json:"position"`
+	Size           int64         `json:"size"`
+	Align          int64         `json:"align"`
+	Padding        int64         `json:"padding"`
+	OptimalSize    int64         `json:"optimal_size"`
+	OptimalOrder   []string      `json:"optimal_order,omitempty"`
+	Hot            bool          `json:"hot"`
+	CacheLines     int64         `json:"cache_lines"`
+	StraddleFields []string      `json:"straddle_fields,omitempty"`
+	Fields         []FieldLayout `json:"fields"`
+}
+
+// Savings is the number of bytes saved by the suggested field order
+func (r StructReport) Savings() int64 {
+	return r.Size - r.OptimalSize
+}
+
+// Problem reports whether the struct should fail a CI check
+func (r StructReport) Problem() bool {
+	return r.Savings() > 0 || (r.Hot && len(r.StraddleFields) > 0)
+}
+
+type Analyzer struct {
+	Sizes     types.Sizes
+	CacheLine int64
+	// names of hot structs, a struct is also hot when its
+	// declaration is preceded by a "layout:hot" comment
+	Hot map[string]bool
+}
+
+func (a *Analyzer) AnalyzeFiles(fset *token.FileSet, files []*ast.File, info *types.Info, pkg *types.Package) []StructReport {
+	hotComments := make(map[*types.TypeName]bool)
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			decl, ok := node.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				return true
+			}
+
+			for _, s := range decl.Specs {
+				spec := s.(*ast.TypeSpec)
+				doc := spec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+
+				if doc != nil && strings.Contains(doc.Text(), "layout:hot") {
+					if name, ok := info.Defs[spec.Name].(*types.TypeName); ok {
+						hotComments[name] = true
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	var reports []StructReport
+	positions := make(map[string]token.Position)
+	for ident, object := range info.Defs {
+		name, ok := object.(*types.TypeName)
+		if !ok || ident.Name == "_" {
+			continue
+		}
+
+		structure, ok := name.Type().Underlying().(*types.Struct)
+		if !ok || structure.NumFields() == 0 {
+			continue
+		}
+
+		// layout of generic structs depends on type arguments
+		if named, ok := name.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			continue
+		}
+		if dependsOnTypeParams(structure) {
+			continue
+		}
+
+		report := a.Analyze(structure)
+		report.Package = pkg.Path()
+		report.Name = name.Name()
+		position := fset.Position(name.Pos())
+		report.Position = position.String()
+		positions[report.Position] = position
+		report.Hot = hotComments[name] || a.Hot[name.Name()] || a.Hot[pkg.Path()+"."+name.Name()]
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		lhs, rhs := positions[reports[i].Position], positions[reports[j].Position]
+		if lhs.Filename != rhs.Filename {
+			return lhs.Filename < rhs.Filename
+		}
+
+		return lhs.Offset < rhs.Offset
+	})
+
+	return reports
+}
+
+// dependsOnTypeParams reports whether the size of the type is unknown
+// until instantiation, like for local types of generic functions
+func dependsOnTypeParams(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Array:
+		return dependsOnTypeParams(t.Elem())
+	case *types.Named:
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if dependsOnTypeParams(t.TypeArgs().At(i)) {
+				return true
+			}
+		}
+		return false
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if dependsOnTypeParams(t.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		// pointers, slices, maps and others have fixed sizes
+		return false
+	}
+}
+
+func (a *Analyzer) Analyze(structure *types.Struct) StructReport {
+	fields := make([]*types.Var, structure.NumFields())
+	for i := range fields {
+		fields[i] = structure.Field(i)
+	}
+
+	offsets := a.Sizes.Offsetsof(fields)
+	report := StructReport{
+		Size:  a.Sizes.Sizeof(structure),
+		Align: a.Sizes.Alignof(structure),
+	}
+
+	used := int64(0)
+	for i, field := range fields {
+		size := a.Sizes.Sizeof(field.Type())
+		end := report.Size
+		if i+1 < len(fields) {
+			end = offsets[i+1]
+		}
+
+		layout := FieldLayout{
+			Name:    field.Name(),
+			Type:    types.TypeString(field.Type(), types.RelativeTo(field.Pkg())),
+			Offset:  offsets[i],
+			Size:    size,
+			Align:   a.Sizes.Alignof(field.Type()),
+			Padding: end - offsets[i] - size,
+		}
+
+		if a.CacheLine > 0 && size > 0 && offsets[i]/a.CacheLine != (offsets[i]+size-1)/a.CacheLine {
+			layout.Straddles = true
+			report.StraddleFields = append(report.StraddleFields, field.Name())
+		}
+
+		used += size
+		report.Fields = append(report.Fields, layout)
+	}
+
+	report.Padding = report.Size - used
+	if a.CacheLine > 0 {
+		report.CacheLines = (report.Size + a.CacheLine - 1) / a.CacheLine
+	}
+
+	optimal := a.optimalOrder(fields)
+	report.OptimalSize = a.Sizes.Sizeof(types.NewStruct(optimal, nil))
+	if report.OptimalSize < report.Size {
+		for _, field := range optimal {
+			report.OptimalOrder = append(report.OptimalOrder, field.Name())
+		}
+	} else {
+		report.OptimalSize = report.Size
+	}
+
+	return report
+}
+
+// optimalOrder sorts fields by alignment and size in descending order,
+// zero-sized fields go first because a trailing one forces padding
+func (a *Analyzer) optimalOrder(fields []*types.Var) []*types.Var {
+	optimal := slices.Clone(fields)
+	sort.SliceStable(optimal, func(i, j int) bool {
+		lhsSize, rhsSize := a.Sizes.Sizeof(optimal[i].Type()), a.Sizes.Sizeof(optimal[j].Type())
+		if (lhsSize == 0) != (rhsSize == 0) {
+			return lhsSize == 0
+		}
+
+		lhsAlign, rhsAlign := a.Sizes.Alignof(optimal[i].Type()), a.Sizes.Alignof(optimal[j].Type())
+		if lhsAlign != rhsAlign {
+			return lhsAlign > rhsAlign
+		}
+
+		return lhsSize > rhsSize
+	})
+
+	return optimal
+}
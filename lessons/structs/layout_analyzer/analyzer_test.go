@@ -0,0 +1,182 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+const source = `package sample
+
+type data1 struct {
+	aaa bool
+	bbb int32
+	ccc bool
+}
+
+type data2 struct {
+	aaa int32
+	bbb bool
+	ccc bool
+}
+
+type withFinalZeroField struct {
+	x int64
+	a struct{}
+}
+
+// Counters is updated by every worker
+// layout:hot
+type Counters struct {
+	flags [60]byte
+	key   [8]byte
+	hits  int64
+}
+
+type Slice struct {
+	data *byte
+	len  int
+	cap  int
+}
+
+func local() {
+	type inner struct {
+		a bool
+		b int64
+		c bool
+	}
+}
+
+type Pair[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type node[T any] struct {
+	value T
+	next  *node[T]
+}
+
+type pairs struct {
+	flag bool
+	pair Pair[int64, bool]
+	ok   bool
+}
+
+func generic[T any]() {
+	type local struct {
+		ok    bool
+		value T
+	}
+
+	type pointers struct {
+		value *T
+		items []T
+	}
+}
+`
+
+func analyze(t *testing.T, arch string, hot map[string]bool) map[string]StructReport {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample.go", source, parser.ParseComments)
+	require.NoError(t, err)
+
+	analyzer := &Analyzer{Sizes: types.SizesFor("gc", arch), CacheLine: 64, Hot: hot}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	config := types.Config{Importer: importer.Default(), Sizes: analyzer.Sizes}
+	pkg, err := config.Check("sample", fset, []*ast.File{file}, info)
+	require.NoError(t, err)
+
+	reports := make(map[string]StructReport)
+	for _, report := range analyzer.AnalyzeFiles(fset, []*ast.File{file}, info, pkg) {
+		reports[report.Name] = report
+	}
+
+	return reports
+}
+
+func TestAnalyze(t *testing.T) {
+	reports := analyze(t, "amd64", nil)
+	require.Len(t, reports, 8)
+
+	data1 := reports["data1"]
+	assert.Equal(t, int64(12), data1.Size)
+	assert.Equal(t, int64(6), data1.Padding)
+	assert.Equal(t, int64(8), data1.OptimalSize)
+	assert.Equal(t, []string{"bbb", "aaa", "ccc"}, data1.OptimalOrder)
+	assert.Equal(t, []int64{3, 0, 3}, []int64{data1.Fields[0].Padding, data1.Fields[1].Padding, data1.Fields[2].Padding})
+	assert.True(t, data1.Problem())
+
+	data2 := reports["data2"]
+	assert.Equal(t, int64(8), data2.Size)
+	assert.Zero(t, data2.Savings())
+	assert.Nil(t, data2.OptimalOrder)
+	assert.False(t, data2.Problem())
+
+	zero := reports["withFinalZeroField"]
+	assert.Equal(t, int64(16), zero.Size)
+	assert.Equal(t, int64(8), zero.OptimalSize)
+	assert.Equal(t, []string{"a", "x"}, zero.OptimalOrder)
+
+	inner := reports["inner"]
+	assert.Equal(t, int64(24), inner.Size)
+	assert.Equal(t, int64(16), inner.OptimalSize)
+}
+
+func TestGenericStructs(t *testing.T) {
+	reports := analyze(t, "amd64", nil)
+
+	// generic structs are skipped, instantiated ones are analysed
+	assert.NotContains(t, reports, "Pair")
+	assert.NotContains(t, reports, "node")
+	assert.NotContains(t, reports, "local")
+
+	pairs := reports["pairs"]
+	assert.Equal(t, int64(32), pairs.Size)
+	assert.Equal(t, int64(24), pairs.OptimalSize)
+
+	assert.Equal(t, int64(32), reports["pointers"].Size)
+}
+
+func TestCacheLines(t *testing.T) {
+	reports := analyze(t, "amd64", nil)
+
+	counters := reports["Counters"]
+	assert.True(t, counters.Hot)
+	assert.Equal(t, int64(80), counters.Size)
+	assert.Equal(t, int64(2), counters.CacheLines)
+	assert.Equal(t, []string{"key"}, counters.StraddleFields)
+	assert.True(t, counters.Problem())
+
+	reports = analyze(t, "amd64", map[string]bool{"sample.Slice": true})
+	assert.True(t, reports["Slice"].Hot)
+	assert.False(t, reports["Slice"].Problem())
+}
+
+func TestArchitectures(t *testing.T) {
+	tests := map[string]struct {
+		arch  string
+		size  int64
+		align int64
+	}{
+		"amd64": {arch: "amd64", size: 24, align: 8},
+		"386":   {arch: "386", size: 12, align: 4},
+		"arm":   {arch: "arm", size: 12, align: 4},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			slice := analyze(t, test.arch, nil)["Slice"]
+			assert.Equal(t, test.size, slice.Size)
+			assert.Equal(t, test.align, slice.Align)
+		})
+	}
+}
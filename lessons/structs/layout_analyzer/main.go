@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// go run . -arch=386 -hot=MutexCounter ../../sync_primitives/...
+// go run . -json -strict ./... > layout.json
+
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+}
+
+func listPackages(patterns []string) ([]listedPackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-e", "-json=ImportPath,Dir,GoFiles"}, patterns...)...)
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	var packages []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+func analyzePackage(analyzer *Analyzer, fset *token.FileSet, imports types.Importer, pkg listedPackage) ([]StructReport, error) {
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	config := types.Config{
+		Importer: imports,
+		Sizes:    analyzer.Sizes,
+		Error:    func(error) {}, // layouts of valid declarations are still useful
+	}
+
+	checked, _ := config.Check(pkg.ImportPath, fset, files, info)
+	return analyzer.AnalyzeFiles(fset, files, info, checked), nil
+}
+
+func printText(w io.Writer, reports []StructReport, verbose bool) {
+	for _, report := range reports {
+		if !verbose && !report.Problem() {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s: %s.%s size=%d align=%d padding=%d", report.Position, report.Package, report.Name, report.Size, report.Align, report.Padding)
+		if report.Savings() > 0 {
+			fmt.Fprintf(w, " optimal=%d (-%d bytes)\n\tsuggested order: %s", report.OptimalSize, report.Savings(), strings.Join(report.OptimalOrder, ", "))
+		}
+
+		if report.Hot && len(report.StraddleFields) > 0 {
+			fmt.Fprintf(w, "\n\thot struct fields straddle cache lines: %s", strings.Join(report.StraddleFields, ", "))
+		}
+
+		fmt.Fprintln(w)
+		if verbose {
+			for _, field := range report.Fields {
+				fmt.Fprintf(w, "\t%-16s %-20s offset=%-4d size=%-4d align=%-2d padding=%d\n",
+					field.Name, field.Type, field.Offset, field.Size, field.Align, field.Padding)
+			}
+		}
+	}
+}
+
+func main() {
+	arch := flag.String("arch", runtime.GOARCH, "target GOARCH")
+	cacheLine := flag.Int64("cacheline", 64, "cache line size in bytes")
+	hot := flag.String("hot", "", "comma-separated names of hot structs (Name or import/path.Name)")
+	jsonOutput := flag.Bool("json", false, "print reports as JSON")
+	verbose := flag.Bool("v", false, "print every struct with its fields")
+	strict := flag.Bool("strict", false, "exit with code 1 when a struct can be shrunk or a hot struct straddles cache lines")
+	flag.Parse()
+
+	sizes := types.SizesFor("gc", *arch)
+	if sizes == nil {
+		fmt.Fprintf(os.Stderr, "unknown architecture %q\n", *arch)
+		os.Exit(2)
+	}
+
+	analyzer := &Analyzer{
+		Sizes:     sizes,
+		CacheLine: *cacheLine,
+		Hot:       make(map[string]bool),
+	}
+
+	for _, name := range strings.Split(*hot, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			analyzer.Hot[name] = true
+		}
+	}
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	packages, err := listPackages(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fset := token.NewFileSet()
+	imports := importer.ForCompiler(fset, "source", nil)
+
+	reports := []StructReport{}
+	for _, pkg := range packages {
+		pkgReports, err := analyzePackage(analyzer, fset, imports, pkg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+
+		reports = append(reports, pkgReports...)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(reports)
+	} else {
+		printText(os.Stdout, reports, *verbose)
+	}
+
+	if *strict {
+		for _, report := range reports {
+			if report.Problem() {
+				os.Exit(1)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package sbo
+
+import (
+	"bytes"
+)
+
+// InlineCapacity is chosen so that SmallBytes takes 48 bytes:
+// 24 for the heap slice, 23 inline and 1 for the length
+const InlineCapacity = 23
+
+// SmallBytes stores up to InlineCapacity bytes inside the struct and
+// moves the data to the heap when it grows beyond that. Unlike the unsafe
+// union from lessons/structs/union_1 both parts are separate fields, so
+// a heap pointer is never reinterpreted as data. The zero value is empty
+type SmallBytes struct {
+	heap   []byte // used when length does not fit inline
+	inline [InlineCapacity]byte
+	length uint8 // length of inline data
+}
+
+func BytesFrom(data []byte) SmallBytes {
+	var b SmallBytes
+	b.Append(data...)
+	return b
+}
+
+func BytesFromString(str string) SmallBytes {
+	var b SmallBytes
+	b.AppendString(str)
+	return b
+}
+
+func (b *SmallBytes) IsInline() bool {
+	return b.heap == nil
+}
+
+func (b *SmallBytes) Len() int {
+	if b.heap != nil {
+		return len(b.heap)
+	}
+
+	return int(b.length)
+}
+
+func (b *SmallBytes) Cap() int {
+	if b.heap != nil {
+		return cap(b.heap)
+	}
+
+	return InlineCapacity
+}
+
+// Bytes returns a view of the data, it is valid until the next modification
+func (b *SmallBytes) Bytes() []byte {
+	if b.heap != nil {
+		return b.heap
+	}
+
+	return b.inline[:b.length]
+}
+
+func (b *SmallBytes) Append(data ...byte) {
+	if b.heap == nil && int(b.length)+len(data) <= InlineCapacity {
+		b.length += uint8(copy(b.inline[b.length:], data))
+		return
+	}
+
+	b.spill(len(data))
+	b.heap = append(b.heap, data...)
+}
+
+func (b *SmallBytes) AppendString(str string) {
+	if b.heap == nil && int(b.length)+len(str) <= InlineCapacity {
+		b.length += uint8(copy(b.inline[b.length:], str))
+		return
+	}
+
+	b.spill(len(str))
+	b.heap = append(b.heap, str...)
+}
+
+func (b *SmallBytes) AppendByte(c byte) {
+	b.Append(c)
+}
+
+// spill moves inline data to the heap with room for extra bytes
+func (b *SmallBytes) spill(extra int) {
+	if b.heap != nil {
+		return
+	}
+
+	b.heap = make([]byte, b.length, max(2*InlineCapacity, int(b.length)+extra))
+	copy(b.heap, b.inline[:b.length])
+	b.length = 0
+}
+
+func (b *SmallBytes) Reset() {
+	*b = SmallBytes{}
+}
+
+// Truncate keeps the first n bytes, data stays on the heap once spilled
+func (b *SmallBytes) Truncate(n int) {
+	if n < 0 || n > b.Len() {
+		panic("sbo: truncation out of range")
+	}
+
+	if b.heap != nil {
+		b.heap = b.heap[:n]
+	} else {
+		b.length = uint8(n)
+	}
+}
+
+// Slice returns a copy of data[from:to], small results are stored inline
+func (b *SmallBytes) Slice(from, to int) SmallBytes {
+	return BytesFrom(b.Bytes()[from:to])
+}
+
+func (b *SmallBytes) Clone() SmallBytes {
+	return BytesFrom(b.Bytes())
+}
+
+func (b *SmallBytes) Equal(other *SmallBytes) bool {
+	return bytes.Equal(b.Bytes(), other.Bytes())
+}
+
+func (b *SmallBytes) Compare(other *SmallBytes) int {
+	return bytes.Compare(b.Bytes(), other.Bytes())
+}
+
+// String copies the data, the compiler avoids the allocation
+// when the result does not escape and is short
+func (b *SmallBytes) String() string {
+	return string(b.Bytes())
+}
@@ -0,0 +1,173 @@
+package sbo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v -bench=. -benchmem .
+
+func TestSize(t *testing.T) {
+	assert.Equal(t, uintptr(48), unsafe.Sizeof(SmallBytes{}))
+	assert.Equal(t, uintptr(40), unsafe.Sizeof(SmallString{}))
+}
+
+func TestSmallBytes(t *testing.T) {
+	var b SmallBytes
+	assert.True(t, b.IsInline())
+	assert.Equal(t, 0, b.Len())
+
+	b.AppendString("user:")
+	b.Append([]byte("12345")...)
+	assert.True(t, b.IsInline())
+	assert.Equal(t, "user:12345", b.String())
+	assert.Equal(t, InlineCapacity, b.Cap())
+
+	b.AppendString(strings.Repeat("x", InlineCapacity-10))
+	assert.True(t, b.IsInline())
+
+	b.AppendByte('!')
+	assert.False(t, b.IsInline())
+	assert.Equal(t, "user:12345"+strings.Repeat("x", InlineCapacity-10)+"!", b.String())
+
+	slice := b.Slice(0, 4)
+	assert.True(t, slice.IsInline())
+	assert.Equal(t, "user", slice.String())
+
+	b.Truncate(4)
+	assert.Equal(t, "user", b.String())
+	assert.True(t, b.Equal(&slice))
+
+	clone := b.Clone()
+	b.AppendString("s")
+	assert.Equal(t, "user", clone.String())
+	assert.Equal(t, -1, clone.Compare(&b))
+
+	b.Reset()
+	assert.True(t, b.IsInline())
+	assert.Equal(t, 0, b.Len())
+	assert.Panics(t, func() { b.Truncate(1) })
+}
+
+func TestSmallBytesCopy(t *testing.T) {
+	original := BytesFromString("short")
+	copied := original
+	copied.AppendString("!")
+
+	assert.Equal(t, "short", original.String())
+	assert.Equal(t, "short!", copied.String())
+}
+
+func TestSmallString(t *testing.T) {
+	tests := map[string]struct {
+		value  string
+		inline bool
+	}{
+		"empty":           {value: "", inline: true},
+		"short":           {value: "key", inline: true},
+		"inline limit":    {value: strings.Repeat("a", InlineCapacity), inline: true},
+		"above the limit": {value: strings.Repeat("a", InlineCapacity+1), inline: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := StringFrom(test.value)
+			assert.Equal(t, test.inline, s.IsInline())
+			assert.Equal(t, test.value, s.String())
+			assert.Equal(t, len(test.value), s.Len())
+
+			fromBytes := StringFromBytes([]byte(test.value))
+			assert.True(t, s.Equal(&fromBytes))
+			assert.Equal(t, []byte(test.value), fromBytes.Bytes())
+		})
+	}
+
+	long := StringFrom("prefix:" + strings.Repeat("z", 40))
+	short := long.Slice(0, 6)
+	assert.True(t, short.IsInline())
+	assert.Equal(t, "prefix", short.String())
+	assert.Equal(t, byte(':'), long.At(6))
+
+	tail := long.Slice(7, 47)
+	assert.False(t, tail.IsInline())
+	assert.Equal(t, unsafe.Add(unsafe.Pointer(unsafe.StringData(long.String())), 7), unsafe.Pointer(unsafe.StringData(tail.String())))
+
+	lhs, rhs := StringFrom("a"), StringFrom("b")
+	assert.Equal(t, -1, lhs.Compare(&rhs))
+
+	aliased := StringFrom("before")
+	str := aliased.String()
+	aliased = StringFrom("after!")
+	assert.Equal(t, "before", str)
+}
+
+func TestAllocations(t *testing.T) {
+	data := []byte("order:42")
+	lookup := map[string]int{"order:42": 1}
+
+	allocations := testing.AllocsPerRun(100, func() {
+		b := BytesFrom(data)
+		b.AppendString(":v2")
+		b.Truncate(len(data))
+		if lookup[b.String()] != 1 {
+			panic("lookup failed")
+		}
+
+		s := StringFromBytes(b.Bytes())
+		if s.String() != "order:42" {
+			panic("comparison failed")
+		}
+	})
+
+	assert.Zero(t, allocations)
+}
+
+var (
+	keys       = []string{"id", "user:1", "session:abcdef", "order:1234567890"}
+	Result     int
+	StringSink string
+	SmallSink  SmallString
+)
+
+func BenchmarkByteSliceKeys(b *testing.B) {
+	lookup := map[string]int{"user:1": 1}
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			buffer := []byte("prefix/")
+			buffer = append(buffer, key...)
+			Result += lookup[string(buffer[7:])]
+			Result += bytes.Compare(buffer, []byte("prefix/user"))
+		}
+	}
+}
+
+func BenchmarkSmallBytesKeys(b *testing.B) {
+	lookup := map[string]int{"user:1": 1}
+	prefix := BytesFromString("prefix/user")
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			buffer := BytesFromString("prefix/")
+			buffer.AppendString(key)
+			Result += lookup[string(buffer.Bytes()[7:])]
+			Result += buffer.Compare(&prefix)
+		}
+	}
+}
+
+func BenchmarkByteSliceToString(b *testing.B) {
+	data := [][]byte{[]byte(keys[0]), []byte(keys[1]), []byte(keys[2]), []byte(keys[3])}
+	for i := 0; i < b.N; i++ {
+		StringSink = string(data[i%len(data)])
+	}
+}
+
+func BenchmarkSmallStringFromBytes(b *testing.B) {
+	data := [][]byte{[]byte(keys[0]), []byte(keys[1]), []byte(keys[2]), []byte(keys[3])}
+	for i := 0; i < b.N; i++ {
+		SmallSink = StringFromBytes(data[i%len(data)])
+	}
+}
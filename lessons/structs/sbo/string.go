@@ -0,0 +1,87 @@
+package sbo
+
+import (
+	"strings"
+)
+
+// SmallString is an immutable string that keeps short values inline,
+// so building it from bytes does not allocate for short values
+type SmallString struct {
+	heap   string
+	inline [InlineCapacity]byte
+	length uint8
+}
+
+func StringFrom(str string) SmallString {
+	if len(str) > InlineCapacity {
+		return SmallString{heap: str}
+	}
+
+	var s SmallString
+	s.length = uint8(copy(s.inline[:], str))
+	return s
+}
+
+func StringFromBytes(data []byte) SmallString {
+	if len(data) > InlineCapacity {
+		return SmallString{heap: string(data)}
+	}
+
+	var s SmallString
+	s.length = uint8(copy(s.inline[:], data))
+	return s
+}
+
+func (s *SmallString) IsInline() bool {
+	return s.length != 0 || s.heap == ""
+}
+
+func (s *SmallString) Len() int {
+	if s.length != 0 {
+		return int(s.length)
+	}
+
+	return len(s.heap)
+}
+
+// String copies inline data instead of aliasing it, because assigning
+// to a SmallString variable would change the returned string. String is
+// inlined, so the compiler avoids the allocation while the result does not escape
+func (s *SmallString) String() string {
+	if s.length != 0 {
+		return string(s.inline[:s.length])
+	}
+
+	return s.heap
+}
+
+func (s *SmallString) At(index int) byte {
+	return s.String()[index]
+}
+
+// Slice is zero-copy for heap values and copies inline ones
+func (s *SmallString) Slice(from, to int) SmallString {
+	if s.length != 0 {
+		return StringFromBytes(s.inline[:s.length][from:to])
+	}
+
+	sub := s.heap[from:to]
+	if len(sub) <= InlineCapacity {
+		return StringFrom(sub)
+	}
+
+	return SmallString{heap: sub}
+}
+
+func (s *SmallString) Equal(other *SmallString) bool {
+	return s.String() == other.String()
+}
+
+func (s *SmallString) Compare(other *SmallString) int {
+	return strings.Compare(s.String(), other.String())
+}
+
+// Bytes returns a copy of the data
+func (s *SmallString) Bytes() []byte {
+	return []byte(s.String())
+}
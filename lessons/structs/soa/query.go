@@ -0,0 +1,87 @@
+package soa
+
+// Query narrows a set of row indexes column by column: the first
+// predicate scans its whole column, every next one only checks rows
+// selected so far, so columns without predicates are never touched
+type Query[T any] struct {
+	table *Table[T]
+	rows  []int
+	all   bool
+	err   error
+}
+
+func Select[T any](t *Table[T]) *Query[T] {
+	return &Query[T]{table: t, all: true}
+}
+
+func Where[C, T any](q *Query[T], name string, predicate func(C) bool) *Query[T] {
+	if q.err != nil {
+		return q
+	}
+
+	values, err := Column[C](q.table, name)
+	if err != nil {
+		q.err = err
+		return q
+	}
+
+	if q.all {
+		q.rows = make([]int, 0)
+		for i, value := range values {
+			if predicate(value) {
+				q.rows = append(q.rows, i)
+			}
+		}
+
+		q.all = false
+		return q
+	}
+
+	selected := q.rows[:0]
+	for _, i := range q.rows {
+		if predicate(values[i]) {
+			selected = append(selected, i)
+		}
+	}
+
+	q.rows = selected
+	return q
+}
+
+// Indexes returns matching row indexes in ascending order
+func (q *Query[T]) Indexes() ([]int, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	if q.all {
+		rows := make([]int, q.table.Len())
+		for i := range rows {
+			rows[i] = i
+		}
+
+		return rows, nil
+	}
+
+	return q.rows, nil
+}
+
+func (q *Query[T]) Count() (int, error) {
+	rows, err := q.Indexes()
+	return len(rows), err
+}
+
+// Rows reconstructs matching rows
+func (q *Query[T]) Rows() ([]T, error) {
+	indexes, err := q.Indexes()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]T, len(indexes))
+	for i, index := range indexes {
+		rows[i] = q.table.Row(index)
+	}
+
+	return rows, nil
+}
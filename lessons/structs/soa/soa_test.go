@@ -0,0 +1,197 @@
+package soa
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v -bench=. .
+
+type Entity struct {
+	ID       int
+	Name     string
+	Health   int
+	Team     string
+	Position [2]float64
+	cache    int
+}
+
+func entities() *Table[Entity] {
+	table := MustNew[Entity]()
+	table.Append(
+		Entity{ID: 1, Name: "archer", Health: 10, Team: "red"},
+		Entity{ID: 2, Name: "knight", Health: 0, Team: "red"},
+		Entity{ID: 3, Name: "mage", Health: 7, Team: "blue", Position: [2]float64{1, 2}},
+		Entity{ID: 4, Name: "rogue", Health: 3, Team: "red", cache: 42},
+	)
+
+	return table
+}
+
+func TestTable(t *testing.T) {
+	table := entities()
+	assert.Equal(t, 4, table.Len())
+	assert.Equal(t, []string{"ID", "Name", "Health", "Team", "Position"}, table.Columns())
+	assert.Equal(t, Entity{ID: 3, Name: "mage", Health: 7, Team: "blue", Position: [2]float64{1, 2}}, table.Row(2))
+	assert.Zero(t, table.Row(3).cache)
+
+	health := MustColumn[int](table, "Health")
+	assert.Equal(t, []int{10, 0, 7, 3}, health)
+
+	health[1] = 5 // columns share storage with the table
+	assert.Equal(t, 5, table.Row(1).Health)
+
+	table.Set(0, Entity{ID: 1, Name: "archer", Health: 11, Team: "blue"})
+	assert.Equal(t, "blue", table.Row(0).Team)
+
+	_, err := Column[string](table, "Health")
+	assert.ErrorIs(t, err, ErrColumnMismatch)
+
+	_, err = Column[int](table, "cache")
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	_, err = New[int]()
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestDelete(t *testing.T) {
+	tests := map[string]struct {
+		index int
+		ids   []int
+	}{
+		"first": {index: 0, ids: []int{4, 2, 3}},
+		"inner": {index: 1, ids: []int{1, 4, 3}},
+		"last":  {index: 3, ids: []int{1, 2, 3}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			table := entities()
+			table.Delete(test.index)
+
+			assert.Equal(t, 3, table.Len())
+			assert.Equal(t, test.ids, MustColumn[int](table, "ID"))
+			assert.Len(t, MustColumn[string](table, "Name"), 3)
+		})
+	}
+
+	table := entities()
+	assert.Panics(t, func() { table.Delete(4) })
+}
+
+func TestQuery(t *testing.T) {
+	table := entities()
+
+	rows, err := Where(Where(Select(table), "Team", func(team string) bool {
+		return team == "red"
+	}), "Health", func(health int) bool {
+		return health > 0
+	}).Rows()
+
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "archer", rows[0].Name)
+	assert.Equal(t, "rogue", rows[1].Name)
+
+	indexes, err := Select(table).Indexes()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, indexes)
+
+	count, err := Where(Select(table), "Health", func(health int) bool { return health > 100 }).Count()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = Where(Where(Select(table), "Level", func(int) bool { return true }), "ID", func(int) bool { return true }).Rows()
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+// types from lessons/structs/dod
+type OODStyle struct {
+	Field1 int
+	Field2 string
+	Field3 int
+	Field4 string
+	Field5 int
+	Field6 string
+	Field7 int
+	Field8 string
+}
+
+func generate(size int) []OODStyle {
+	r := rand.New(rand.NewSource(42))
+	data := make([]OODStyle, size)
+	for i := range data {
+		data[i] = OODStyle{
+			Field1: r.Intn(1000),
+			Field2: fmt.Sprintf("field2-%d", r.Intn(1000)),
+			Field3: r.Intn(1000),
+			Field4: fmt.Sprintf("field4-%d", r.Intn(1000)),
+			Field5: r.Intn(1000),
+			Field6: fmt.Sprintf("field6-%d", r.Intn(1000)),
+			Field7: r.Intn(1000),
+			Field8: fmt.Sprintf("fiel8-%d", r.Intn(1000)),
+		}
+	}
+
+	return data
+}
+
+var Sink int
+
+func BenchmarkOOD(b *testing.B) {
+	data := generate(1_000_000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j := range data {
+			if data[j].Field1 == 500 && data[j].Field3 < 10 {
+				Sink = j
+			}
+		}
+	}
+}
+
+func BenchmarkTableAppend(b *testing.B) {
+	data := generate(100_000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		table := MustNew[OODStyle]()
+		for j := range data {
+			table.Append(data[j])
+		}
+
+		Sink = table.Len()
+	}
+}
+
+func BenchmarkTableColumns(b *testing.B) {
+	table := MustNew[OODStyle]()
+	table.Append(generate(1_000_000)...)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		field1 := MustColumn[int](table, "Field1")
+		field3 := MustColumn[int](table, "Field3")
+		for j := range field1 {
+			if field1[j] == 500 && field3[j] < 10 {
+				Sink = j
+			}
+		}
+	}
+}
+
+func BenchmarkTableQuery(b *testing.B) {
+	table := MustNew[OODStyle]()
+	table.Append(generate(1_000_000)...)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		query := Where(Select(table), "Field1", func(value int) bool { return value == 500 })
+		Sink, _ = Where(query, "Field3", func(value int) bool { return value < 10 }).Count()
+	}
+}
@@ -0,0 +1,170 @@
+package soa
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrNotStruct      = errors.New("row type is not a struct")
+	ErrUnknownColumn  = errors.New("unknown column")
+	ErrColumnMismatch = errors.New("column type mismatch")
+)
+
+type column struct {
+	name   string
+	field  int
+	values reflect.Value // []FieldType
+}
+
+// Table stores exported fields of T as parallel slices (struct of arrays),
+// unexported fields are not stored and are zero in reconstructed rows
+type Table[T any] struct {
+	columns []column
+	index   map[string]int
+	length  int
+}
+
+func New[T any]() (*Table[T], error) {
+	rowType := reflect.TypeFor[T]()
+	if rowType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrNotStruct, rowType)
+	}
+
+	t := &Table[T]{index: make(map[string]int)}
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		t.index[field.Name] = len(t.columns)
+		t.columns = append(t.columns, column{
+			name:   field.Name,
+			field:  i,
+			values: reflect.MakeSlice(reflect.SliceOf(field.Type), 0, 0),
+		})
+	}
+
+	return t, nil
+}
+
+func MustNew[T any]() *Table[T] {
+	t, err := New[T]()
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func (t *Table[T]) Len() int {
+	return t.length
+}
+
+func (t *Table[T]) Columns() []string {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+func (t *Table[T]) Grow(n int) {
+	for i := range t.columns {
+		c := &t.columns[i]
+		if c.values.Cap()-c.values.Len() < n {
+			// geometric growth keeps appends of single rows amortized O(1)
+			capacity := max(2*c.values.Cap(), c.values.Len()+n)
+			grown := reflect.MakeSlice(c.values.Type(), c.values.Len(), capacity)
+			reflect.Copy(grown, c.values)
+			c.values = grown
+		}
+	}
+}
+
+func (t *Table[T]) Append(rows ...T) {
+	t.Grow(len(rows))
+	for _, row := range rows {
+		value := reflect.ValueOf(row)
+		for i := range t.columns {
+			c := &t.columns[i]
+			c.values = reflect.Append(c.values, value.Field(c.field))
+		}
+	}
+
+	t.length += len(rows)
+}
+
+// Delete moves the last row into the deleted position, so row order is not kept
+func (t *Table[T]) Delete(i int) {
+	if i < 0 || i >= t.length {
+		panic(fmt.Sprintf("soa: index %d out of range [0:%d]", i, t.length))
+	}
+
+	last := t.length - 1
+	for j := range t.columns {
+		c := &t.columns[j]
+		if i != last {
+			c.values.Index(i).Set(c.values.Index(last))
+		}
+
+		c.values.Index(last).SetZero() // release references for GC
+		c.values = c.values.Slice(0, last)
+	}
+
+	t.length--
+}
+
+func (t *Table[T]) Row(i int) T {
+	var row T
+	value := reflect.ValueOf(&row).Elem()
+	for _, c := range t.columns {
+		value.Field(c.field).Set(c.values.Index(i))
+	}
+
+	return row
+}
+
+func (t *Table[T]) Set(i int, row T) {
+	value := reflect.ValueOf(row)
+	for _, c := range t.columns {
+		c.values.Index(i).Set(value.Field(c.field))
+	}
+}
+
+func (t *Table[T]) column(name string) (column, error) {
+	i, found := t.index[name]
+	if !found {
+		return column{}, fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+	}
+
+	return t.columns[i], nil
+}
+
+// Column returns the typed slice backing the column, writes to it change the
+// table and it stays valid until the next Append, Grow or Delete
+func Column[C, T any](t *Table[T], name string) ([]C, error) {
+	c, err := t.column(name)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := c.values.Interface().([]C)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is %s", ErrColumnMismatch, name, c.values.Type().Elem())
+	}
+
+	return values, nil
+}
+
+func MustColumn[C, T any](t *Table[T], name string) []C {
+	values, err := Column[C](t, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return values
+}
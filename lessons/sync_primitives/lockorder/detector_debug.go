@@ -0,0 +1,239 @@
+//go:build debug
+
+package lockorder
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type heldLock struct {
+	id        uint64
+	stack     string
+	exclusive bool
+}
+
+type edgeKey struct {
+	from uint64
+	to   uint64
+}
+
+type lockDetector struct {
+	mutex    sync.Mutex
+	held     map[int][]heldLock // by goroutine
+	owners   map[uint64]int     // goroutines holding exclusive locks
+	edges    map[uint64]map[uint64]Edge
+	reported map[edgeKey]struct{}
+}
+
+var detector = &lockDetector{
+	held:     make(map[int][]heldLock),
+	owners:   make(map[uint64]int),
+	edges:    make(map[uint64]map[uint64]Edge),
+	reported: make(map[edgeKey]struct{}),
+}
+
+func acquire(id uint64, exclusive bool, tryLock func() bool, lock func()) {
+	goroutine, stack := currentGoroutine()
+	detector.check(id, exclusive, goroutine, stack)
+
+	config.mutex.Lock()
+	timeout, onTimeout := config.waitTimeout, config.onTimeout
+	config.mutex.Unlock()
+
+	if timeout > 0 && !tryLock() {
+		start := time.Now()
+		timer := time.AfterFunc(timeout, func() {
+			onTimeout(&WaitReport{
+				Lock:        id,
+				Waited:      time.Since(start),
+				WaitStack:   stack,
+				HolderStack: detector.holderStack(id),
+				Goroutines:  allGoroutines(),
+			})
+		})
+
+		lock()
+		timer.Stop()
+	} else if timeout <= 0 {
+		lock()
+	}
+
+	detector.acquired(id, exclusive, goroutine, stack)
+}
+
+func tryAcquire(id uint64, exclusive bool, tryLock func() bool) bool {
+	// a failed attempt can't deadlock, so the order isn't checked
+	if !tryLock() {
+		return false
+	}
+
+	goroutine, stack := currentGoroutine()
+	detector.acquired(id, exclusive, goroutine, stack)
+	return true
+}
+
+// check adds edges from held locks to the new one before waiting,
+// so a report is made even if the program hangs right after it
+func (d *lockDetector) check(id uint64, exclusive bool, goroutine int, stack string) {
+	var reports []*Report
+
+	d.mutex.Lock()
+	for _, held := range d.held[goroutine] {
+		edge := Edge{From: held.id, To: id, Goroutine: goroutine, HeldStack: held.stack, AcquireStack: stack}
+		if held.id == id {
+			// only shared acquisitions of the same lock can be nested
+			if (exclusive || held.exclusive) && d.markReported(edge) {
+				reports = append(reports, &Report{Cycle: []Edge{edge}})
+			}
+			continue
+		}
+
+		if _, found := d.edges[held.id][id]; !found {
+			if d.edges[held.id] == nil {
+				d.edges[held.id] = make(map[uint64]Edge)
+			}
+			d.edges[held.id][id] = edge
+		}
+
+		if path := d.path(id, held.id, make(map[uint64]bool)); path != nil && d.markReported(edge) {
+			reports = append(reports, &Report{Cycle: append(path, edge)})
+		}
+	}
+	d.mutex.Unlock()
+
+	config.mutex.Lock()
+	onDeadlock := config.onDeadlock
+	config.mutex.Unlock()
+
+	for _, report := range reports {
+		onDeadlock(report)
+	}
+}
+
+func (d *lockDetector) markReported(edge Edge) bool {
+	key := edgeKey{from: edge.From, to: edge.To}
+	if _, found := d.reported[key]; found {
+		return false
+	}
+
+	d.reported[key] = struct{}{}
+	return true
+}
+
+// path searches edges leading from one lock to another with DFS
+func (d *lockDetector) path(from, to uint64, visited map[uint64]bool) []Edge {
+	visited[from] = true
+	for next, edge := range d.edges[from] {
+		if next == to {
+			return []Edge{edge}
+		}
+
+		if visited[next] {
+			continue
+		}
+
+		if rest := d.path(next, to, visited); rest != nil {
+			return append([]Edge{edge}, rest...)
+		}
+	}
+
+	return nil
+}
+
+func (d *lockDetector) acquired(id uint64, exclusive bool, goroutine int, stack string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.held[goroutine] = append(d.held[goroutine], heldLock{id: id, stack: stack, exclusive: exclusive})
+	if exclusive {
+		d.owners[id] = goroutine
+	}
+}
+
+// release removes the lock from the goroutine which holds it,
+// a mutex can be unlocked by another goroutine
+func (d *lockDetector) release(id uint64, exclusive bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if exclusive {
+		if owner, found := d.owners[id]; found {
+			delete(d.owners, id)
+			d.remove(owner, id)
+		}
+		return
+	}
+
+	goroutine, _ := currentGoroutine()
+	if d.remove(goroutine, id) {
+		return
+	}
+
+	for holder := range d.held {
+		if d.remove(holder, id) {
+			return
+		}
+	}
+}
+
+func (d *lockDetector) remove(goroutine int, id uint64) bool {
+	held := d.held[goroutine]
+	for i := len(held) - 1; i >= 0; i-- {
+		if held[i].id != id {
+			continue
+		}
+
+		held = append(held[:i], held[i+1:]...)
+		if len(held) == 0 {
+			delete(d.held, goroutine)
+		} else {
+			d.held[goroutine] = held
+		}
+		return true
+	}
+
+	return false
+}
+
+func (d *lockDetector) holderStack(id uint64) string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	owner, found := d.owners[id]
+	if !found {
+		return ""
+	}
+
+	for _, held := range d.held[owner] {
+		if held.id == id {
+			return held.stack
+		}
+	}
+
+	return ""
+}
+
+// currentGoroutine parses "goroutine 7 [running]:" from the stack
+func currentGoroutine() (int, string) {
+	buffer := make([]byte, 8<<10)
+	stack := buffer[:runtime.Stack(buffer, false)]
+
+	header, _, _ := bytes.Cut(stack, []byte(" ["))
+	id, _ := strconv.Atoi(string(bytes.TrimPrefix(header, []byte("goroutine "))))
+	return id, string(stack)
+}
+
+func allGoroutines() string {
+	buffer := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) {
+			return string(buffer[:n])
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+}
@@ -0,0 +1,152 @@
+//go:build debug
+
+package lockorder
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureReports(t *testing.T) <-chan *Report {
+	reports := make(chan *Report, 10)
+	OnPotentialDeadlock(func(report *Report) { reports <- report })
+	t.Cleanup(func() { OnPotentialDeadlock(nil) })
+	return reports
+}
+
+func normalizeResources(lhs, rhs *Mutex) {
+	lhs.Lock()
+	rhs.Lock()
+
+	rhs.Unlock()
+	lhs.Unlock()
+}
+
+func TestInconsistentOrderIsReportedWithoutHang(t *testing.T) {
+	reports := captureReports(t)
+
+	var mutex1, mutex2 Mutex
+	normalizeResources(&mutex1, &mutex2)
+	assert.Empty(t, reports)
+
+	// the same order from another goroutine is fine
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		normalizeResources(&mutex1, &mutex2)
+	}()
+	<-done
+	assert.Empty(t, reports)
+
+	normalizeResources(&mutex2, &mutex1)
+	require.Len(t, reports, 1)
+
+	report := <-reports
+	require.Len(t, report.Cycle, 2)
+	assert.Equal(t, report.Cycle[0].From, report.Cycle[1].To)
+	assert.Equal(t, report.Cycle[0].To, report.Cycle[1].From)
+	assert.Contains(t, report.Cycle[0].AcquireStack, "normalizeResources")
+	assert.Contains(t, report.String(), "potential deadlock")
+
+	// the same cycle is reported once
+	normalizeResources(&mutex2, &mutex1)
+	assert.Empty(t, reports)
+}
+
+func TestLongCycle(t *testing.T) {
+	reports := captureReports(t)
+
+	var a, b, c RWMutex
+	lock := func(first, second *RWMutex) {
+		first.RLock()
+		second.Lock()
+		second.Unlock()
+		first.RUnlock()
+	}
+
+	lock(&a, &b)
+	lock(&b, &c)
+	assert.Empty(t, reports)
+
+	lock(&c, &a)
+	require.Len(t, reports, 1)
+	assert.Len(t, (<-reports).Cycle, 3)
+}
+
+func TestRecursiveLock(t *testing.T) {
+	reports := captureReports(t)
+
+	var mutex Mutex
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mutex.Lock()
+		mutex.Lock()
+	}()
+
+	report := <-reports
+	require.Len(t, report.Cycle, 1)
+	assert.Equal(t, report.Cycle[0].From, report.Cycle[0].To)
+
+	// unlocking from another goroutine lets it continue
+	mutex.Unlock()
+	wg.Wait()
+	mutex.Unlock()
+}
+
+func TestReadLockUnderWriteLock(t *testing.T) {
+	reports := captureReports(t)
+
+	var mutex RWMutex
+	mutex.RLock()
+	mutex.RLock()
+	mutex.RUnlock()
+	mutex.RUnlock()
+	assert.Empty(t, reports)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mutex.Lock()
+		mutex.RLock()
+		mutex.RUnlock()
+	}()
+
+	report := <-reports
+	require.Len(t, report.Cycle, 1)
+	assert.Equal(t, report.Cycle[0].From, report.Cycle[0].To)
+
+	mutex.Unlock()
+	wg.Wait()
+}
+
+func TestWaitTimeout(t *testing.T) {
+	reports := make(chan *WaitReport, 1)
+	SetWaitTimeout(10*time.Millisecond, func(report *WaitReport) { reports <- report })
+	defer SetWaitTimeout(0, nil)
+
+	var mutex Mutex
+	mutex.Lock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mutex.Lock()
+		mutex.Unlock()
+	}()
+
+	report := <-reports
+	assert.GreaterOrEqual(t, report.Waited, 10*time.Millisecond)
+	assert.Contains(t, report.HolderStack, "TestWaitTimeout")
+	assert.Contains(t, report.Goroutines, "goroutine")
+	assert.Contains(t, report.String(), "is not acquired for")
+
+	mutex.Unlock()
+	<-done
+}
@@ -0,0 +1,72 @@
+package lockorder
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v .
+// go test -v -tags debug .
+
+func TestMutexesWork(t *testing.T) {
+	var mutex Mutex
+	var rwMutex RWMutex
+	counter := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rwMutex.RLock()
+			defer rwMutex.RUnlock()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, counter)
+	assert.True(t, mutex.TryLock())
+	assert.False(t, mutex.TryLock())
+	mutex.Unlock()
+
+	rwMutex.Lock()
+	assert.False(t, rwMutex.TryRLock())
+	rwMutex.Unlock()
+
+	var locker sync.Locker = rwMutex.RLocker()
+	locker.Lock()
+	assert.False(t, rwMutex.TryLock())
+	locker.Unlock()
+}
+
+// both builds must have the same API, so code compiles with and without the tag
+func TestNoExportedFields(t *testing.T) {
+	for _, value := range []any{Mutex{}, RWMutex{}} {
+		typ := reflect.TypeOf(value)
+		for i := 0; i < typ.NumField(); i++ {
+			assert.False(t, typ.Field(i).IsExported(), "%s.%s", typ.Name(), typ.Field(i).Name)
+		}
+	}
+
+	var _ interface {
+		sync.Locker
+		TryLock() bool
+	} = &Mutex{}
+
+	var _ interface {
+		sync.Locker
+		TryLock() bool
+		RLock()
+		TryRLock() bool
+		RUnlock()
+		RLocker() sync.Locker
+	} = &RWMutex{}
+}
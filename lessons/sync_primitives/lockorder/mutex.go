@@ -0,0 +1,59 @@
+//go:build !debug
+
+package lockorder
+
+import "sync"
+
+// Enabled reports whether the detector is compiled in, build with -tags debug to enable it
+const Enabled = false
+
+// Mutex is sync.Mutex without any overhead in normal builds,
+// the field is unexported to keep the API of the debug build
+type Mutex struct {
+	mutex sync.Mutex
+}
+
+func (m *Mutex) Lock() {
+	m.mutex.Lock()
+}
+
+func (m *Mutex) TryLock() bool {
+	return m.mutex.TryLock()
+}
+
+func (m *Mutex) Unlock() {
+	m.mutex.Unlock()
+}
+
+// RWMutex is sync.RWMutex without any overhead in normal builds
+type RWMutex struct {
+	mutex sync.RWMutex
+}
+
+func (m *RWMutex) Lock() {
+	m.mutex.Lock()
+}
+
+func (m *RWMutex) TryLock() bool {
+	return m.mutex.TryLock()
+}
+
+func (m *RWMutex) Unlock() {
+	m.mutex.Unlock()
+}
+
+func (m *RWMutex) RLock() {
+	m.mutex.RLock()
+}
+
+func (m *RWMutex) TryRLock() bool {
+	return m.mutex.TryRLock()
+}
+
+func (m *RWMutex) RUnlock() {
+	m.mutex.RUnlock()
+}
+
+func (m *RWMutex) RLocker() sync.Locker {
+	return m.mutex.RLocker()
+}
@@ -0,0 +1,88 @@
+//go:build debug
+
+package lockorder
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const Enabled = true
+
+var lastLockID atomic.Uint64
+
+// lockID is assigned on the first use, addresses can't be used
+// because memory of a freed mutex can be reused for another one
+type lockID struct {
+	value atomic.Uint64
+}
+
+func (l *lockID) get() uint64 {
+	if id := l.value.Load(); id != 0 {
+		return id
+	}
+
+	l.value.CompareAndSwap(0, lastLockID.Add(1))
+	return l.value.Load()
+}
+
+// Mutex records the order of acquisitions and reports potential deadlocks
+type Mutex struct {
+	mutex sync.Mutex
+	id    lockID
+}
+
+func (m *Mutex) Lock() {
+	acquire(m.id.get(), true, m.mutex.TryLock, m.mutex.Lock)
+}
+
+func (m *Mutex) TryLock() bool {
+	return tryAcquire(m.id.get(), true, m.mutex.TryLock)
+}
+
+func (m *Mutex) Unlock() {
+	detector.release(m.id.get(), true)
+	m.mutex.Unlock()
+}
+
+// RWMutex records the order of acquisitions and reports potential deadlocks,
+// read locks are treated like write ones because they also wait for writers
+type RWMutex struct {
+	mutex sync.RWMutex
+	id    lockID
+}
+
+func (m *RWMutex) Lock() {
+	acquire(m.id.get(), true, m.mutex.TryLock, m.mutex.Lock)
+}
+
+func (m *RWMutex) TryLock() bool {
+	return tryAcquire(m.id.get(), true, m.mutex.TryLock)
+}
+
+func (m *RWMutex) Unlock() {
+	detector.release(m.id.get(), true)
+	m.mutex.Unlock()
+}
+
+func (m *RWMutex) RLock() {
+	acquire(m.id.get(), false, m.mutex.TryRLock, m.mutex.RLock)
+}
+
+func (m *RWMutex) TryRLock() bool {
+	return tryAcquire(m.id.get(), false, m.mutex.TryRLock)
+}
+
+func (m *RWMutex) RUnlock() {
+	detector.release(m.id.get(), false)
+	m.mutex.RUnlock()
+}
+
+func (m *RWMutex) RLocker() sync.Locker {
+	return (*rlocker)(m)
+}
+
+type rlocker RWMutex
+
+func (r *rlocker) Lock()   { (*RWMutex)(r).RLock() }
+func (r *rlocker) Unlock() { (*RWMutex)(r).RUnlock() }
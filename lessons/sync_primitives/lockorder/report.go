@@ -0,0 +1,94 @@
+package lockorder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Edge means that a goroutine acquired To while holding From
+type Edge struct {
+	From         uint64
+	To           uint64
+	Goroutine    int
+	HeldStack    string // where From was acquired
+	AcquireStack string // where To was acquired
+}
+
+// Report describes locks acquired in inconsistent order,
+// the last edge closes the cycle and is observed right now
+type Report struct {
+	Cycle []Edge
+}
+
+func (r *Report) String() string {
+	var builder strings.Builder
+	builder.WriteString("potential deadlock: inconsistent lock order")
+	for _, edge := range r.Cycle {
+		fmt.Fprintf(&builder, "\n\ngoroutine %d acquired lock %d holding lock %d", edge.Goroutine, edge.To, edge.From)
+		fmt.Fprintf(&builder, "\nlock %d was acquired at:\n%s", edge.From, edge.HeldStack)
+		fmt.Fprintf(&builder, "\nlock %d was acquired at:\n%s", edge.To, edge.AcquireStack)
+	}
+
+	return builder.String()
+}
+
+// WaitReport describes a goroutine waiting for a lock longer than the timeout
+type WaitReport struct {
+	Lock        uint64
+	Waited      time.Duration
+	WaitStack   string
+	HolderStack string // empty if the holder is unknown
+	Goroutines  string // stacks of all goroutines
+}
+
+func (r *WaitReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "lock %d is not acquired for %v, waiting at:\n%s", r.Lock, r.Waited, r.WaitStack)
+	if r.HolderStack != "" {
+		fmt.Fprintf(&builder, "\nheld since:\n%s", r.HolderStack)
+	}
+	fmt.Fprintf(&builder, "\nall goroutines:\n%s", r.Goroutines)
+	return builder.String()
+}
+
+func printReport(report fmt.Stringer) {
+	fmt.Fprintln(os.Stderr, report)
+}
+
+var config = struct {
+	mutex       sync.Mutex
+	onDeadlock  func(*Report)
+	waitTimeout time.Duration
+	onTimeout   func(*WaitReport)
+}{
+	onDeadlock: func(report *Report) { printReport(report) },
+	onTimeout:  func(report *WaitReport) { printReport(report) },
+}
+
+// OnPotentialDeadlock replaces printing of reports to stderr, nil handler
+// restores it, it has an effect only in builds with the debug tag
+func OnPotentialDeadlock(handler func(*Report)) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+
+	config.onDeadlock = handler
+	if handler == nil {
+		config.onDeadlock = func(report *Report) { printReport(report) }
+	}
+}
+
+// SetWaitTimeout enables reports about long waits, zero timeout disables them,
+// nil handler prints reports to stderr, it has an effect only in debug builds
+func SetWaitTimeout(timeout time.Duration, handler func(*WaitReport)) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+
+	config.waitTimeout = timeout
+	config.onTimeout = handler
+	if handler == nil {
+		config.onTimeout = func(report *WaitReport) { printReport(report) }
+	}
+}
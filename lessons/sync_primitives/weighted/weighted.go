@@ -0,0 +1,147 @@
+package weighted
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+type waiter struct {
+	n     int64
+	ready chan struct{} // closed when the weight is granted
+}
+
+// Semaphore limits the total weight of concurrent holders, waiters
+// are served strictly in order, so a large request isn't starved
+// by a stream of small ones
+type Semaphore struct {
+	mutex   sync.Mutex
+	size    int64
+	current int64
+	waiters list.List
+}
+
+func New(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire waits for the weight or returns the context error, a request
+// larger than the size waits until the size grows, blocking requests after it
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n < 0 {
+		panic("weighted: negative weight")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		s.mutex.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	element := s.waiters.PushBack(waiter{n: n, ready: ready})
+	s.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	select {
+	case <-ready:
+		// granted right after cancellation, the weight is given back
+		s.current -= n
+		s.notifyWaiters()
+	default:
+		front := s.waiters.Front() == element
+		s.waiters.Remove(element)
+
+		// the next waiters could be blocked only by this one
+		if front {
+			s.notifyWaiters()
+		}
+	}
+
+	return ctx.Err()
+}
+
+// TryAcquire takes the weight without waiting, it fails if there are waiters
+func (s *Semaphore) TryAcquire(n int64) bool {
+	if n < 0 {
+		panic("weighted: negative weight")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		return true
+	}
+
+	return false
+}
+
+// Release gives back the weight, releasing more than acquired is a bug
+func (s *Semaphore) Release(n int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if n < 0 || n > s.current {
+		panic("weighted: released more than held")
+	}
+
+	s.current -= n
+	s.notifyWaiters()
+}
+
+// Resize changes the limit, after shrinking holders keep their weight
+// and new requests wait until the total goes below the new size
+func (s *Semaphore) Resize(size int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.size = size
+	s.notifyWaiters()
+}
+
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(waiter)
+		if s.size-s.current < w.n {
+			// waiters behind must not overtake the first one
+			return
+		}
+
+		s.current += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+type Stats struct {
+	Size     int64
+	Acquired int64
+	Waiters  int
+}
+
+func (s *Semaphore) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return Stats{Size: s.size, Acquired: s.current, Waiters: s.waiters.Len()}
+}
@@ -0,0 +1,184 @@
+package weighted
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// go test -v .
+
+func waitForWaiters(t *testing.T, s *Semaphore, count int) {
+	require.Eventually(t, func() bool {
+		return s.Stats().Waiters == count
+	}, time.Second, time.Millisecond)
+}
+
+func TestAcquireRelease(t *testing.T) {
+	s := New(10)
+	ctx := context.Background()
+
+	require.NoError(t, s.Acquire(ctx, 3))
+	require.NoError(t, s.Acquire(ctx, 7))
+	assert.False(t, s.TryAcquire(1))
+	assert.Equal(t, Stats{Size: 10, Acquired: 10}, s.Stats())
+
+	s.Release(7)
+	assert.True(t, s.TryAcquire(5))
+	assert.True(t, s.TryAcquire(0))
+	s.Release(8)
+	assert.Zero(t, s.Stats().Acquired)
+}
+
+func TestOverRelease(t *testing.T) {
+	s := New(2)
+	assert.Panics(t, func() { s.Release(1) })
+
+	require.True(t, s.TryAcquire(1))
+	assert.Panics(t, func() { s.Release(2) })
+	assert.Panics(t, func() { s.TryAcquire(-1) })
+}
+
+func TestFIFO(t *testing.T) {
+	s := New(10)
+	ctx := context.Background()
+	require.NoError(t, s.Acquire(ctx, 5))
+
+	// the large request waits and small ones must not overtake it
+	large := make(chan struct{})
+	go func() {
+		_ = s.Acquire(ctx, 10)
+		close(large)
+	}()
+	waitForWaiters(t, s, 1)
+
+	assert.False(t, s.TryAcquire(1))
+
+	small := make(chan struct{})
+	go func() {
+		_ = s.Acquire(ctx, 1)
+		close(small)
+	}()
+	waitForWaiters(t, s, 2)
+
+	s.Release(5)
+	<-large
+	select {
+	case <-small:
+		t.Fatal("small request overtook the large one")
+	default:
+	}
+
+	s.Release(10)
+	<-small
+}
+
+func TestOrderOfWaiters(t *testing.T) {
+	s := New(1)
+	ctx := context.Background()
+	require.NoError(t, s.Acquire(ctx, 1))
+
+	var order []int
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.Acquire(ctx, 1)
+
+			mutex.Lock()
+			order = append(order, i)
+			mutex.Unlock()
+			s.Release(1)
+		}()
+		waitForWaiters(t, s, i+1)
+	}
+
+	s.Release(1)
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestCancellation(t *testing.T) {
+	s := New(2)
+	require.True(t, s.TryAcquire(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		result <- s.Acquire(ctx, 2)
+	}()
+	waitForWaiters(t, s, 1)
+
+	// the small request behind the cancelled one can proceed
+	small := make(chan error)
+	go func() {
+		small <- s.Acquire(context.Background(), 1)
+	}()
+	waitForWaiters(t, s, 2)
+
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+	require.NoError(t, <-small)
+	assert.Equal(t, Stats{Size: 2, Acquired: 2}, s.Stats())
+
+	assert.ErrorIs(t, s.Acquire(ctx, 0), context.Canceled)
+}
+
+func TestResize(t *testing.T) {
+	s := New(1)
+	ctx := context.Background()
+	require.NoError(t, s.Acquire(ctx, 1))
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = s.Acquire(ctx, 3)
+		close(acquired)
+	}()
+	waitForWaiters(t, s, 1)
+
+	s.Resize(4)
+	<-acquired
+	assert.Equal(t, Stats{Size: 4, Acquired: 4}, s.Stats())
+
+	// holders keep their weight after shrinking
+	s.Resize(2)
+	s.Release(3)
+	assert.False(t, s.TryAcquire(2))
+	assert.True(t, s.TryAcquire(1))
+}
+
+func TestLimit(t *testing.T) {
+	s := New(3)
+	var active, maxActive atomic.Int64
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			weight := int64(i%3 + 1)
+			require.NoError(t, s.Acquire(context.Background(), weight))
+			defer s.Release(weight)
+
+			current := active.Add(weight)
+			for {
+				previous := maxActive.Load()
+				if current <= previous || maxActive.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-weight)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxActive.Load(), int64(3))
+}